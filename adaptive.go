@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// The number of samples simulated between checks of the stopping criteria.
+const adaptiveBatchSamples = 1000
+
+// The z-score of a 95% confidence level.
+const defaultZ = 1.96
+
+// Options of an adaptive simulation. It stops at whichever of the precision,
+// time budget or number of samples is reached first, so at least one of them
+// must be set.
+type AdaptiveOptions struct {
+	// Simulate the rest of the race rather than the current leg.
+	Race bool
+	// The target half-width of every (camel, rank) confidence interval.
+	Precision float64
+	// Of the confidence level, defaults to 1.96 for 95%.
+	Z          float64
+	TimeBudget time.Duration
+	MaxSamples int
+}
+
+// The result of an adaptive simulation.
+type SimulationResult struct {
+	Distribution *RankingDistribution
+	Z            float64
+	// Whether the target precision was reached.
+	Converged bool
+}
+
+// Simulates the current leg, or the rest of the race, in batches until the
+// confidence interval of every (camel, rank) probability is narrow enough, or
+// the time budget or number of samples runs out.
+func (g *Game) SimulateAdaptive(o AdaptiveOptions) (*SimulationResult, error) {
+	if o.Precision <= 0 && o.TimeBudget <= 0 && o.MaxSamples <= 0 {
+		return nil, fmt.Errorf("no precision, time budget or number of samples to stop at")
+	}
+	if o.Z == 0 {
+		o.Z = defaultZ
+	}
+	result := &SimulationResult{Distribution: &RankingDistribution{}, Z: o.Z}
+	start := time.Now()
+	for {
+		n := adaptiveBatchSamples
+		if o.MaxSamples > 0 {
+			n = min(n, o.MaxSamples-result.Distribution.TotalRankings)
+		}
+		if o.Race {
+			g.simulateRace(n, result.Distribution)
+		} else {
+			g.simulateLeg(n, result.Distribution)
+		}
+		if o.Precision > 0 && result.MaxHalfWidth() <= o.Precision {
+			result.Converged = true
+			return result, nil
+		}
+		if o.MaxSamples > 0 && result.Distribution.TotalRankings >= o.MaxSamples ||
+			o.TimeBudget > 0 && time.Since(start) >= o.TimeBudget {
+			return result, nil
+		}
+	}
+}
+
+// Returns the Wilson score confidence interval of the probability of the
+// camel finishing at the given rank.
+func (r *SimulationResult) ConfidenceInterval(c Color, k Rank) (float64, float64) {
+	n := float64(r.Distribution.TotalRankings)
+	p := r.Distribution.Probability(c, k)
+	z2 := r.Z * r.Z
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	halfWidth := r.Z / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return center - halfWidth, center + halfWidth
+}
+
+// Returns the half-width of the widest confidence interval.
+func (r *SimulationResult) MaxHalfWidth() float64 {
+	result := 0.0
+	for c := Green; c < Black; c++ {
+		for k := Last; k <= First; k++ {
+			lo, hi := r.ConfidenceInterval(c, k)
+			result = max(result, (hi-lo)/2)
+		}
+	}
+	return result
+}
+
+func (r *SimulationResult) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Total rankings: %d, widest interval: ±%.2f%%\n", r.Distribution.TotalRankings, r.MaxHalfWidth()*100)
+	headerPattern := strings.Repeat("\t%21s", 5)
+	s.WriteString(colorPrinters[White](headerPattern+"\n", "Last", "4th", "3rd", "2nd", "First"))
+	for c := Green; c < Black; c++ {
+		fmt.Fprintf(&s, "%s", c)
+		for k := Last; k <= First; k++ {
+			lo, hi := r.ConfidenceInterval(c, k)
+			fmt.Fprintf(&s, "\t%5.2f%% [%5.2f%%, %5.2f%%]", r.Distribution.Probability(c, k)*100, lo*100, hi*100)
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
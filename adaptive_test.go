@@ -0,0 +1,98 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSimulateAdaptive(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			0: {Blue, Green, Red, Yellow, Purple},
+			5: {White, Black},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A high confidence level, so that the exact probabilities are all within
+	// their intervals.
+	got, err := g.SimulateAdaptive(AdaptiveOptions{Precision: 0.03, Z: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Converged || got.MaxHalfWidth() > 0.03 {
+		t.Errorf("want converged intervals within 0.03, got:\n%s", got)
+	}
+	want := g.ComputeLegRankingDistribution()
+	for c := Green; c < Black; c++ {
+		for r := Last; r <= First; r++ {
+			lo, hi := got.ConfidenceInterval(c, r)
+			if p := want.Probability(c, r); p < lo || p > hi {
+				t.Errorf("want %s rank %d probability %f within [%f, %f]", c, r, p, lo, hi)
+			}
+		}
+	}
+
+	got, err = g.SimulateAdaptive(AdaptiveOptions{Precision: 1e-6, MaxSamples: 2500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Converged || got.Distribution.TotalRankings != 2500 {
+		t.Errorf("want 2500 samples without converging, got:\n%s", got)
+	}
+
+	start := time.Now()
+	got, err = g.SimulateAdaptive(AdaptiveOptions{Race: true, Precision: 1e-6, TimeBudget: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Converged || got.Distribution.TotalRankings == 0 || time.Since(start) > time.Second {
+		t.Errorf("want to stop after about 50ms, got after %s:\n%s", time.Since(start), got)
+	}
+
+	if _, err := g.SimulateAdaptive(AdaptiveOptions{Z: 2}); err == nil {
+		t.Error("want error without anything to stop at")
+	}
+}
+
+func TestSimulateAdaptiveMidLeg(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob"},
+		Camels: map[BoardPosition][]Color{
+			0: {Red},
+			2: {Blue, Green},
+			4: {Yellow, Purple},
+			9: {White, Black},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []DieRoll{{Purple, 3}, {Green, 2}, {Red, 1}} {
+		if err := g.ApplyMove(Move{Type: RollDie, Player: g.CurrentPlayer(), DieRoll: r}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	remaining := slices.Clone(g.diePyramid.RemainingDice())
+	got, err := g.SimulateAdaptive(AdaptiveOptions{Precision: 0.03, Z: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := g.ComputeLegRankingDistribution()
+	for c := Green; c < Black; c++ {
+		for r := Last; r <= First; r++ {
+			lo, hi := got.ConfidenceInterval(c, r)
+			if p := want.Probability(c, r); p < lo || p > hi {
+				t.Errorf("want %s rank %d probability %f within [%f, %f]", c, r, p, lo, hi)
+			}
+		}
+	}
+	if rolled := g.diePyramid.RemainingRolls(); rolled != 2 || !slices.Equal(g.diePyramid.RemainingDice(), remaining) {
+		t.Errorf("want the pyramid left as it was, got %v", g.diePyramid.RemainingDice())
+	}
+	if err := g.ApplyMove(Move{Type: RollDie, Player: g.CurrentPlayer(), DieRoll: DieRoll{Green, 1}}); err == nil {
+		t.Error("want error rolling the green die twice in a leg")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import "sort"
+
+// A possible action of a player, together with its expected coin gain.
+type Advice struct {
+	Move Move
+	EV   float64
+}
+
+const (
+	// Taking a pyramid ticket is worth one coin at the end of the leg.
+	pyramidTicketValue = 1
+	// The number of simulated races used to value the overall bets.
+	adviceRaceSamples = 10000
+)
+
+// Lists every legal action of the player in the current game state, from the
+// most to the least profitable. There are none if it is not the player's turn.
+// Applying the roll advice rolls the pyramid. A leg that is already over is
+// ended first, as ApplyMove would, on a copy of the game.
+func Advise(g *Game, player Player) []Advice {
+	var result []Advice
+	if !g.gameOver && g.LegOver() {
+		next := &Game{}
+		next.copyFrom(g)
+		next.endFinishedLeg()
+		g = next
+	}
+	if g.checkTurn(player) != nil {
+		return result
+	}
+	d := g.ComputeLegRankingDistribution()
+	if !g.diePyramid.IsEmpty() {
+		result = append(result, Advice{Move{Type: RollDie, Player: player, RollPyramid: true}, pyramidTicketValue})
+	}
+	for _, ev := range g.LegTicketEVs(d) {
+		result = append(result, Advice{Move{Type: BuyTicket, Player: player, Color: ev.Ticket.Color}, ev.EV})
+	}
+	for _, t := range g.OptimizeTilePlacement(player) {
+		result = append(result, Advice{t.Move, t.EV})
+	}
+	race := g.SimulateRaceDistribution(adviceRaceSamples)
+	for c := Green; c < Black; c++ {
+		if !g.HasFinishCard(player, c) {
+			continue
+		}
+		result = append(result,
+			Advice{Move{Type: BetOnWinner, Player: player, Color: c}, overallBetEV(race.Probability(c, First), overallBetPayout(g.winnerBets, c))},
+			Advice{Move{Type: BetOnLoser, Player: player, Color: c}, overallBetEV(race.Probability(c, Last), overallBetPayout(g.loserBets, c))})
+	}
+	pacts := g.PactEVs(player)
+	for q := range g.players {
+		if ev, ok := pacts[Player(q)]; ok {
+			result = append(result, Advice{Move{Type: MakePact, Player: player, Partner: Player(q)}, ev})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].EV > result[j].EV
+	})
+	return result
+}
+
+// Returns the expected value of an overall bet that pays the given payout
+// with probability p.
+func overallBetEV(p float64, payout int) float64 {
+	return float64(payout)*p - wrongOverallBetCost*(1-p)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAdvise(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob"},
+		Camels: map[BoardPosition][]Color{
+			0:  {Yellow, Green, Red},
+			5:  {Blue},
+			9:  {Purple},
+			13: {Black, White},
+		},
+		DiePyramid: NewDiePyramidWithDice(r, []Color{Blue, Purple}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[Move]float64{
+		{Type: RollDie, RollPyramid: true}: 1,
+		{Type: BuyTicket, Color: Yellow}:   -1,
+		{Type: BuyTicket, Color: Blue}:     1,
+		{Type: BuyTicket, Color: Purple}:   5,
+		{Type: PlaceCheer, Position: 6}:    float64(1) / 6,
+		{Type: PlaceBoo, Position: 7}:      float64(1) / 6,
+		{Type: PlaceCheer, Position: 12}:   float64(1) / 6,
+		{Type: PlaceBoo, Position: 14}:     0,
+	}
+	advice := Advise(g, 0)
+	numTileMoves := 0
+	for i, a := range advice {
+		if i > 0 && advice[i-1].EV < a.EV {
+			t.Errorf("want advice sorted by EV, got %v before %v", advice[i-1], a)
+		}
+		if a.Move.Type == PlaceCheer || a.Move.Type == PlaceBoo {
+			numTileMoves++
+		}
+		if ev, ok := want[a.Move]; ok {
+			if math.Abs(ev-a.EV) > 1e-9 {
+				t.Errorf("want EV %5.2f for %s, got %5.2f", ev, a.Move, a.EV)
+			}
+			delete(want, a.Move)
+		}
+	}
+	for m := range want {
+		t.Errorf("missing advice for %s", m)
+	}
+	// Every empty space except the start one, on both sides of the tile.
+	if numTileMoves != 2*12 {
+		t.Errorf("want %d tile moves, got %d", 2*12, numTileMoves)
+	}
+}
+
+func TestAdviseTurn(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	input := &GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			0:  {Yellow, Green, Red, Blue, Purple},
+			13: {Black, White},
+		},
+		DiePyramid: NewDiePyramidWithDice(r, []Color{Blue, Purple}),
+	}
+	g, err := NewGameFromState(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if advice := Advise(g, 0); advice != nil {
+		t.Errorf("want no advice in a game with no players, got %v", advice)
+	}
+	input.Players = []string{"Alice", "Bob"}
+	if g, err = NewGameFromState(input); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []Player{-1, 1, 2} {
+		if advice := Advise(g, p); advice != nil {
+			t.Errorf("want no advice for player %d, got %v", p, advice)
+		}
+	}
+	// A game set up with no rolls left gets advice for the next leg, which
+	// ApplyMove starts before the move.
+	over, err := NewGameFromState(&GameStateInput{
+		Players:         input.Players,
+		Camels:          input.Camels,
+		LegTicketsTaken: map[Color]int{Purple: 1},
+		DiePyramid:      NewDiePyramidWithDice(r, []Color{Purple}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	advice := Advise(over, 0)
+	if !over.LegOver() {
+		t.Errorf("want the game unchanged by advising, got:\n%s", over)
+	}
+	purple := Move{Type: BuyTicket, Color: Purple}
+	if !slices.ContainsFunc(advice, func(a Advice) bool { return a.Move == purple }) {
+		t.Errorf("want a purple ticket advised, got %v", advice)
+	}
+	if err := over.ApplyMove(purple); err != nil {
+		t.Errorf("want the advised ticket bought, got: %v", err)
+	}
+	if tickets := over.LegTickets(0); len(tickets) != 1 || tickets[0].Value != SecondEdition.LegTicketValues[0] {
+		t.Errorf("want the top purple ticket of the next leg, got %v", tickets)
+	}
+	for _, a := range Advise(g, 0) {
+		if a.Move.Type != RollDie {
+			continue
+		}
+		if err := g.ApplyMove(a.Move); err != nil {
+			t.Fatalf("applying %s: %v", a.Move, err)
+		}
+		if len(Advise(g, 1)) == 0 {
+			t.Error("want advice for the next player")
+		}
+		return
+	}
+	t.Error("missing roll advice")
+}
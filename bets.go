@@ -0,0 +1,82 @@
+package main
+
+import "fmt"
+
+// The payouts of the correct overall bets, in the order they were placed.
+// Any further correct bets pay the last value.
+var overallBetPayouts = []int{8, 5, 3, 2, 1}
+
+// What a wrong overall bet costs.
+const wrongOverallBetCost = 1
+
+// A finish card placed on the overall winner or loser stack.
+type OverallBet struct {
+	Player Player
+	Color  Color
+}
+
+// Returns whether the player still has the camel's finish card in hand.
+func (g *Game) HasFinishCard(p Player, c Color) bool {
+	return c.IsRacing() && !g.players[p].FinishCardsUsed[c]
+}
+
+// Returns the overall winner bets, in placement order.
+func (g *Game) WinnerBets() []OverallBet {
+	return g.winnerBets
+}
+
+// Returns the overall loser bets, in placement order.
+func (g *Game) LoserBets() []OverallBet {
+	return g.loserBets
+}
+
+func (g *Game) betOnWinner(p Player, c Color) error {
+	return g.placeOverallBet(p, c, &g.winnerBets)
+}
+
+func (g *Game) betOnLoser(p Player, c Color) error {
+	return g.placeOverallBet(p, c, &g.loserBets)
+}
+
+func (g *Game) placeOverallBet(p Player, c Color, stack *[]OverallBet) error {
+	if err := checkRacingCamel(c); err != nil {
+		return err
+	}
+	if !g.HasFinishCard(p, c) {
+		return fmt.Errorf("%s has no %s finish card", g.PlayerName(p), c)
+	}
+	g.players[p].FinishCardsUsed[c] = true
+	*stack = append(*stack, OverallBet{p, c})
+	return nil
+}
+
+// Returns what an overall bet would pay if correct, given the bets already
+// on its stack.
+func overallBetPayout(stack []OverallBet, c Color) int {
+	correct := 0
+	for _, b := range stack {
+		if b.Color == c {
+			correct++
+		}
+	}
+	return overallBetPayouts[min(correct, len(overallBetPayouts)-1)]
+}
+
+// Pays out the overall winner and loser bets once the race is over.
+func (g *Game) scoreGame() {
+	earnings := make([]int, len(g.players))
+	scoreStack := func(stack []OverallBet, c Color) {
+		for i, b := range stack {
+			if b.Color == c {
+				earnings[b.Player] += overallBetPayout(stack[:i], c)
+			} else {
+				earnings[b.Player] -= wrongOverallBetCost
+			}
+		}
+	}
+	scoreStack(g.winnerBets, g.ranking[First])
+	scoreStack(g.loserBets, g.ranking[Last])
+	for p, e := range earnings {
+		g.players[p].Coins = max(0, g.players[p].Coins+e)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestScoreGame(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob", "Carol"},
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green},
+			3:  {Red, Blue},
+			8:  {Purple},
+			14: {White, Black},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []struct {
+		winner bool
+		bet    OverallBet
+	}{
+		{true, OverallBet{0, Purple}},
+		{true, OverallBet{1, Green}},
+		{false, OverallBet{2, Yellow}},
+		{true, OverallBet{2, Purple}},
+		{false, OverallBet{0, Red}},
+		{true, OverallBet{1, Purple}},
+	} {
+		bet := g.betOnLoser
+		if b.winner {
+			bet = g.betOnWinner
+		}
+		if err := bet(b.bet.Player, b.bet.Color); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.betOnLoser(0, Purple); err == nil {
+		t.Error("want error betting with a finish card that was already used")
+	}
+	g.scoreGame()
+	wantCoins := []int{
+		3 + 8 - 1, // First on the winner, wrong loser.
+		3 - 1 + 3, // Wrong winner, third on the winner.
+		3 + 8 + 5, // First on the loser, second on the winner.
+	}
+	for p, want := range wantCoins {
+		if got := g.Coins(Player(p)); got != want {
+			t.Errorf("want %s to have %d coins, got %d", g.PlayerName(Player(p)), want, got)
+		}
+	}
+}
@@ -7,7 +7,11 @@ import (
 	"github.com/fatih/color"
 )
 
-type Color int
+type Color int8
+
+// Stands for a missing color, such as the grey die of a game without crazy
+// camels.
+const NoColor Color = -1
 
 const (
 	Green Color = iota
@@ -59,10 +63,25 @@ func ParseColor(s string) (Color, error) {
 	return White, fmt.Errorf("unknown color: %s", s)
 }
 
+func (c Color) IsRacing() bool {
+	return c >= Green && c < Black
+}
+
 func (c Color) IsCrazy() bool {
 	return c >= Black
 }
 
+// Checks that the color is a racing camel's, such as for a ticket or a bet.
+func checkRacingCamel(c Color) error {
+	if c < Green || c > White {
+		return fmt.Errorf("invalid camel color: %d", c)
+	}
+	if !c.IsRacing() {
+		return fmt.Errorf("%s camel is not a racing camel", c)
+	}
+	return nil
+}
+
 func (c Color) String() string {
 	return colorPrinters[c](colorNames[c])
 }
@@ -18,38 +18,85 @@ func (r *DieRoll) String() string {
 
 type DiePyramid struct {
 	r        *rand.Rand
+	rules    *RuleSet
 	numRolls int
 	dice     []Color
 }
 
 var ErrOutOfDice = fmt.Errorf("out of dice")
 
-// Creates a pyramid with all 6 dice available (Black stands for the grey die).
+// Creates a pyramid with all 6 dice of the second edition available.
 func NewDiePyramid(r *rand.Rand) *DiePyramid {
-	return NewDiePyramidWithDice(r, []Color{Green, Yellow, Red, Blue, Purple, Black})
+	return SecondEdition.NewDiePyramid(r)
 }
 
 // Creates a die pyramid with only the specified N dice in it, prepared for rolling
 // N-1 of them. Used for simulations/computations where some of the dice have
 // been already rolled out.
-// TODO: validate input ([2-6], unique colors, no White); copy input slice.
 func NewDiePyramidWithDice(r *rand.Rand, dice []Color) *DiePyramid {
-	result := &DiePyramid{r: r, dice: dice}
+	return SecondEdition.NewDiePyramidWithDice(r, dice)
+}
+
+// Creates a pyramid with all the dice of the rule set available.
+func (rs *RuleSet) NewDiePyramid(r *rand.Rand) *DiePyramid {
+	return rs.NewDiePyramidWithDice(r, rs.Dice)
+}
+
+// Creates a die pyramid of the rule set with only the specified dice in it,
+// prepared for rolling the ones remaining in the leg.
+// TODO: validate input (unique colors of the rule set's dice).
+func (rs *RuleSet) NewDiePyramidWithDice(r *rand.Rand, dice []Color) *DiePyramid {
+	result := &DiePyramid{r: r, rules: rs, dice: append(make([]Color, 0, len(rs.Dice)), dice...)}
 	result.Reset()
 	return result
 }
 
 // Resets the pyramid to the starting dice.
 func (p *DiePyramid) Reset() {
-	// Shuffle the colors (Black stands for the grey die).
+	// Shuffle the colors.
 	p.r.Shuffle(len(p.dice), func(i, j int) {
 		p.dice[i], p.dice[j] = p.dice[j], p.dice[i]
 	})
 	p.numRolls = 0
 }
 
+// Shuffles the dice left in the pyramid, keeping the ones already taken out.
+func (p *DiePyramid) shuffleRemaining() {
+	dice := p.dice[p.numRolls:]
+	p.r.Shuffle(len(dice), func(i, j int) {
+		dice[i], dice[j] = dice[j], dice[i]
+	})
+}
+
+// Puts all the dice back into the pyramid and shuffles them, for a new leg.
+func (p *DiePyramid) Refill() {
+	p.dice = append(p.dice[:0], p.rules.Dice...)
+	p.Reset()
+}
+
+// Copies the state of another pyramid into this one. The source of randomness
+// is kept.
+func (p *DiePyramid) copyFrom(o *DiePyramid) {
+	p.dice = append(p.dice[:0], o.dice...)
+	p.numRolls = o.numRolls
+}
+
 func (p *DiePyramid) RemainingRolls() int {
-	return len(p.dice) - 1 - p.numRolls
+	return len(p.dice) - p.rules.diceLeftPerLeg() - p.numRolls
+}
+
+// Returns the number of ways the remaining rolls of the leg can go, by the
+// number of rolls left: with M rolls left it is 6^M times the number of ways
+// to draw M dice in order out of the M+K in the pyramid, where K dice are
+// left at the end of the leg. The grey die has 6 outcomes, so every other die
+// counts each of its 3 values twice.
+func (p *DiePyramid) remainingWeights() [NumMovesPerLeg]int {
+	var result [NumMovesPerLeg]int
+	result[0] = 1
+	for m := 1; m < NumMovesPerLeg; m++ {
+		result[m] = result[m-1] * (p.rules.diceLeftPerLeg() + m) * 6
+	}
+	return result
 }
 
 func (p *DiePyramid) RemainingDice() []Color {
@@ -60,16 +107,34 @@ func (p *DiePyramid) IsEmpty() bool {
 	return p.RemainingRolls() == 0
 }
 
-func (p *DiePyramid) Roll() (DieRoll, error) {
-	result := DieRoll{-1, -1}
-	if p.numRolls == len(p.dice)-1 {
-		return result, ErrOutOfDice
+// Takes the die of the given color out of the pyramid, for a roll that
+// happened at the table. Either crazy camel stands for the grey die as well.
+func (p *DiePyramid) Take(c Color) error {
+	if p.IsEmpty() {
+		return ErrOutOfDice
 	}
-	result.Color = p.dice[p.numRolls]
-	if result.Color == Black { // Grey, can be Black/White
-		result.Color = Color(p.r.Intn(2)) + Black
+	c = p.rules.camelDie(c)
+	for i := p.numRolls; i < len(p.dice); i++ {
+		if p.dice[i] == c {
+			p.dice[i], p.dice[p.numRolls] = p.dice[p.numRolls], p.dice[i]
+			p.numRolls++
+			return nil
+		}
 	}
-	result.Value = RollValue(p.r.Intn(3) + 1)
+	return fmt.Errorf("%s die is not in the pyramid", c)
+}
+
+func (p *DiePyramid) Roll() (DieRoll, error) {
+	if p.IsEmpty() {
+		return DieRoll{-1, -1}, ErrOutOfDice
+	}
+	result := p.rollDie(p.dice[p.numRolls])
 	p.numRolls++
 	return result, nil
 }
+
+// Rolls the die of the given color without taking it out of the pyramid.
+func (p *DiePyramid) rollDie(c Color) DieRoll {
+	maxValue, _ := p.rules.dieValues(c)
+	return p.rules.dieRoll(c, RollValue(p.r.Intn(int(maxValue))+1))
+}
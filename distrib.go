@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"strings"
 )
 
@@ -10,6 +11,11 @@ import (
 // enumeration of all possible leg results. It counts all the possible rankings
 // for all the racing camels.
 
+// Anything that counts rankings, from enumerations or simulations.
+type rankingRecorder interface {
+	RecordWeightedRanking(ranking *[NumRacingCamels]Color, weight int)
+}
+
 type RankingDistribution struct {
 	TotalRankings int
 	// Rank x Color
@@ -27,6 +33,27 @@ func (d *RankingDistribution) RecordRanking(ranking *[NumRacingCamels]Color) {
 	d.RecordWeightedRanking(ranking, 1)
 }
 
+// Adds the rankings counted in the other distribution to this one.
+func (d *RankingDistribution) merge(o *RankingDistribution) {
+	d.mergeScaled(o, 1)
+}
+
+// Adds the rankings counted in the other distribution to this one, each
+// counted the given number of times.
+func (d *RankingDistribution) mergeScaled(o *RankingDistribution, scale int) {
+	d.TotalRankings += scale * o.TotalRankings
+	for c := range d.Rankings {
+		for r := range d.Rankings[c] {
+			d.Rankings[c][r] += scale * o.Rankings[c][r]
+		}
+	}
+}
+
+// Returns the probability of the camel finishing at the given rank.
+func (d *RankingDistribution) Probability(c Color, r Rank) float64 {
+	return float64(d.Rankings[c][r]) / float64(d.TotalRankings)
+}
+
 func (d *RankingDistribution) String() string {
 	var s strings.Builder
 	fmt.Fprintf(&s, "Total rankings: %d\n", d.TotalRankings)
@@ -45,3 +72,230 @@ func (d *RankingDistribution) String() string {
 	}
 	return s.String()
 }
+
+// Returns the exact probability of the camel finishing at the given rank, or
+// zero for an empty distribution.
+func (d *RankingDistribution) Rat(c Color, r Rank) *big.Rat {
+	if d.TotalRankings == 0 {
+		return new(big.Rat)
+	}
+	return big.NewRat(int64(d.Rankings[c][r]), int64(d.TotalRankings))
+}
+
+// Returns the lowest common denominator of all the probabilities, which is 1
+// for an empty distribution.
+func (d *RankingDistribution) Denominator() *big.Int {
+	if d.TotalRankings == 0 {
+		return big.NewInt(1)
+	}
+	gcd := big.NewInt(int64(d.TotalRankings))
+	for c := range d.Rankings {
+		for _, n := range d.Rankings[c] {
+			gcd.GCD(nil, nil, gcd, big.NewInt(int64(n)))
+		}
+	}
+	return gcd.Div(big.NewInt(int64(d.TotalRankings)), gcd)
+}
+
+// Like String, but prints the exact probabilities as reduced fractions.
+func (d *RankingDistribution) FractionsString() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Common denominator: %s\n", d.Denominator())
+	width := 2*len(d.Denominator().String()) + 1
+	headerPattern := strings.Repeat(fmt.Sprintf("\t%%%ds", width), 5)
+	s.WriteString(colorPrinters[White](headerPattern+"\n", "Last", "4th", "3rd", "2nd", "First"))
+	for c := Green; c < Black; c++ {
+		fmt.Fprintf(&s, "%s", c)
+		for r := Last; r <= First; r++ {
+			fmt.Fprintf(&s, "\t%*s", width, d.Rat(c, r).RatString())
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// A pair distribution counts the joint outcomes of the first and second
+// ranked camels, which is all that leg ticket payouts depend on. Unlike the
+// per-camel ranks of a ranking distribution, it keeps the correlation between
+// them.
+type PairDistribution struct {
+	TotalRankings int
+	// First x second.
+	Pairs [NumRacingCamels][NumRacingCamels]int
+}
+
+func (d *PairDistribution) RecordWeightedRanking(ranking *[NumRacingCamels]Color, weight int) {
+	d.TotalRankings += weight
+	d.Pairs[ranking[First]][ranking[First-1]] += weight
+}
+
+func (d *PairDistribution) RecordRanking(ranking *[NumRacingCamels]Color) {
+	d.RecordWeightedRanking(ranking, 1)
+}
+
+// Returns the probability of the camels finishing first and second.
+func (d *PairDistribution) Probability(first, second Color) float64 {
+	return float64(d.Pairs[first][second]) / float64(d.TotalRankings)
+}
+
+func (d *PairDistribution) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Total rankings: %d\n", d.TotalRankings)
+	s.WriteString(colorPrinters[White]("1st \\ 2nd"))
+	for c := Green; c < Black; c++ {
+		fmt.Fprintf(&s, "\t%s", c)
+	}
+	for first := Green; first < Black; first++ {
+		fmt.Fprintf(&s, "\n%s", first)
+		for second := Green; second < Black; second++ {
+			fmt.Fprintf(&s, "\t%5.2f%%", d.Probability(first, second)*100)
+		}
+	}
+	s.WriteString("\n")
+	return s.String()
+}
+
+// A race end distribution counts, over all the possible outcomes of a leg, the
+// ones where the race ends, by how it ends.
+type RaceEndDistribution struct {
+	TotalOutcomes int
+	// By the camel at the bottom of the stack that crossed the finish line.
+	// A crazy camel crosses it backwards, past the start.
+	Crossings [NumCamels]int
+	// By the overall winner, of the outcomes where the race ends.
+	Winners [NumRacingCamels]int
+}
+
+// Returns the probability of the race ending during the leg.
+func (d *RaceEndDistribution) Probability() float64 {
+	n := 0
+	for _, k := range d.Crossings {
+		n += k
+	}
+	return float64(n) / float64(d.TotalOutcomes)
+}
+
+// Returns the probability of the race ending with the camel's stack crossing
+// the finish line.
+func (d *RaceEndDistribution) CrossingProbability(c Color) float64 {
+	return float64(d.Crossings[c]) / float64(d.TotalOutcomes)
+}
+
+// Returns the probability of the race ending with a crazy camel crossing the
+// finish line backwards.
+func (d *RaceEndDistribution) CrazyCrossingProbability() float64 {
+	return d.CrossingProbability(Black) + d.CrossingProbability(White)
+}
+
+// Returns the probability of the race ending during the leg, won by the camel.
+func (d *RaceEndDistribution) WinnerProbability(c Color) float64 {
+	return float64(d.Winners[c]) / float64(d.TotalOutcomes)
+}
+
+func (d *RaceEndDistribution) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Total outcomes: %d\n", d.TotalOutcomes)
+	fmt.Fprintf(&s, "Race ends this leg: %5.2f%%\n", d.Probability()*100)
+	s.WriteString(colorPrinters[White]("\tCrosses\tWins\n"))
+	for c := Green; c <= White; c++ {
+		fmt.Fprintf(&s, "%s\t%5.2f%%", c, d.CrossingProbability(c)*100)
+		if c.IsRacing() {
+			fmt.Fprintf(&s, "\t%5.2f%%", d.WinnerProbability(c)*100)
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// A landing distribution counts, over all the possible outcomes of a leg, how
+// many times a camel stack lands on each board space. A landing is where the
+// die roll takes the stack, before a cheer/boo tile moves it on, so it is what
+// pays the tile owner.
+type LandingDistribution struct {
+	BoardSize     int
+	TotalOutcomes int
+	// Weighted number of landings per board space.
+	Landings [MaxBoardSize]int
+}
+
+// Adds the landings counted in the other distribution to this one.
+func (d *LandingDistribution) merge(o *LandingDistribution) {
+	d.TotalOutcomes += o.TotalOutcomes
+	for p := range d.Landings {
+		d.Landings[p] += o.Landings[p]
+	}
+}
+
+// Returns the expected number of landings on the space during the leg.
+func (d *LandingDistribution) ExpectedLandings(p BoardPosition) float64 {
+	return float64(d.Landings[p]) / float64(d.TotalOutcomes)
+}
+
+func (d *LandingDistribution) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Total outcomes: %d\n", d.TotalOutcomes)
+	for p := StartPosition; int(p) < d.BoardSize; p++ {
+		fmt.Fprintf(&s, "%2d: %5.3f\n", p+1, d.ExpectedLandings(p))
+	}
+	return s.String()
+}
+
+// A position distribution counts, over all the possible outcomes of a leg,
+// where each camel ends up: on which board space, and at which height in the
+// stack there. A camel that crossed the finish line is on the space it went
+// on to past the start.
+type PositionDistribution struct {
+	BoardSize     int
+	TotalOutcomes int
+	// Camel x board space x height in the stack, from the bottom up.
+	Outcomes [NumCamels][MaxBoardSize][NumCamels]int
+}
+
+// Returns the probability of the camel ending the leg on the space, at the
+// height in its stack.
+func (d *PositionDistribution) Probability(c Color, p BoardPosition, h int) float64 {
+	return float64(d.Outcomes[c][p][h]) / float64(d.TotalOutcomes)
+}
+
+// Returns the probability of the camel ending the leg on the space.
+func (d *PositionDistribution) PositionProbability(c Color, p BoardPosition) float64 {
+	n := 0
+	for _, k := range d.Outcomes[c][p] {
+		n += k
+	}
+	return float64(n) / float64(d.TotalOutcomes)
+}
+
+// Returns the probability of the camel ending the leg at the height in its
+// stack, from the bottom up, whatever the space.
+func (d *PositionDistribution) HeightProbability(c Color, h int) float64 {
+	n := 0
+	for p := range d.Outcomes[c] {
+		n += d.Outcomes[c][p][h]
+	}
+	return float64(n) / float64(d.TotalOutcomes)
+}
+
+func (d *PositionDistribution) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Total outcomes: %d\n", d.TotalOutcomes)
+	s.WriteString(colorPrinters[White]("Space"))
+	for c := Green; c <= White; c++ {
+		fmt.Fprintf(&s, "\t%s", c)
+	}
+	for p := StartPosition; int(p) < d.BoardSize; p++ {
+		fmt.Fprintf(&s, "\n%5d", p+1)
+		for c := Green; c <= White; c++ {
+			fmt.Fprintf(&s, "\t%5.2f%%", d.PositionProbability(c, p)*100)
+		}
+	}
+	s.WriteString(colorPrinters[White]("\nHeight"))
+	for h := range NumCamels {
+		fmt.Fprintf(&s, "\n%5d", h+1)
+		for c := Green; c <= White; c++ {
+			fmt.Fprintf(&s, "\t%5.2f%%", d.HeightProbability(c, h)*100)
+		}
+	}
+	s.WriteString("\n")
+	return s.String()
+}
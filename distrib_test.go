@@ -0,0 +1,50 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestRankingDistributionRat(t *testing.T) {
+	d := &RankingDistribution{
+		TotalRankings: 72,
+		Rankings: [NumRacingCamels][NumRacingCamels]int{
+			Green:  {0, 0, 0, 44, 28},
+			Yellow: {0, 72, 0, 0, 0},
+			Red:    {72, 0, 0, 0, 0},
+			Blue:   {0, 0, 0, 28, 44},
+			Purple: {0, 0, 72, 0, 0},
+		},
+	}
+	for _, tc := range []struct {
+		c    Color
+		r    Rank
+		want *big.Rat
+	}{
+		{Green, First, big.NewRat(7, 18)},
+		{Blue, First, big.NewRat(11, 18)},
+		{Red, Last, big.NewRat(1, 1)},
+		{Red, First, big.NewRat(0, 1)},
+	} {
+		if got := d.Rat(tc.c, tc.r); got.Cmp(tc.want) != 0 {
+			t.Errorf("Rat(%s, %d) = %s, want %s", tc.c, tc.r, got, tc.want)
+		}
+	}
+	if got := d.Denominator(); got.Cmp(big.NewInt(18)) != 0 {
+		t.Errorf("Denominator() = %s, want 18", got)
+	}
+	if s := d.FractionsString(); !strings.Contains(s, "7/18") || !strings.Contains(s, "11/18") {
+		t.Errorf("want fractions 7/18 and 11/18 in:\n%s", s)
+	}
+	empty := &RankingDistribution{}
+	if got := empty.Rat(Green, First); got.Sign() != 0 {
+		t.Errorf("Rat(%s, %d) = %s on an empty distribution, want 0", Green, First, got)
+	}
+	if got := empty.Denominator(); got.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("Denominator() = %s on an empty distribution, want 1", got)
+	}
+	if s := empty.FractionsString(); !strings.Contains(s, "Common denominator: 1") {
+		t.Errorf("want denominator 1 in:\n%s", s)
+	}
+}
@@ -3,32 +3,37 @@ package main
 import (
 	"fmt"
 	"math/rand"
+	"slices"
 	"strings"
 )
 
 type BoardPosition int
 
 const (
-	StartPosition  BoardPosition = 0
-	FinishPosition BoardPosition = 15
-	BoardSize                    = 16
+	StartPosition    BoardPosition = 0
+	NoPosition       BoardPosition = -1
+	DefaultBoardSize               = 16
+	// Enough room to set up the camels without any of them stacking up.
+	MinBoardSize = 6
+	MaxBoardSize = 32
 )
 
-func (p BoardPosition) Add(k int) BoardPosition {
-	return BoardPosition((BoardSize + int(p) + k) % BoardSize)
+// Returns the position k spaces on (or back, for negative k), wrapping around
+// a board of the given size.
+func (p BoardPosition) Add(k, boardSize int) BoardPosition {
+	return BoardPosition((boardSize + int(p) + k) % boardSize)
 }
 
-// A camel token on the board.
+// Where a camel token is on the board.
 type camel struct {
-	Color      Color
-	Position   BoardPosition
-	Next       *camel // The camel on top of this one.
-	Prev       *camel // The camel below this one.
-	OtherCrazy *camel // White<->Black
+	Position BoardPosition
+	Height   int8 // In its stack, from the bottom up.
 }
 
-func (c *camel) IsCrazy() bool {
-	return c.Color.IsCrazy()
+// The camels on a board space, from the bottom up.
+type camelStack struct {
+	Camels [NumCamels]Color
+	Height int8
 }
 
 type Player int
@@ -36,10 +41,9 @@ type Player int
 const NoPlayer Player = -1
 
 type boardSpace struct {
-	Cheer       Player
-	Boo         Player
-	StackBottom *camel
-	StackTop    *camel
+	Cheer Player
+	Boo   Player
+	Stack camelStack
 }
 
 func (s *boardSpace) HasCheer() bool {
@@ -52,6 +56,7 @@ func (s *boardSpace) HasBoo() bool {
 
 type Rank int
 
+// NumCamels and NumMovesPerLeg are the most any rule set uses.
 const (
 	Last            Rank = 0
 	First           Rank = 4
@@ -61,31 +66,47 @@ const (
 )
 
 type undoableMove struct {
-	stackBottom *camel
-	stackTop    *camel
+	roll        DieRoll
+	ranking     [NumRacingCamels]Color // Before the move.
+	camel       Color                  // At the bottom of the moved stack.
+	numCamels   int8
 	srcPos      BoardPosition
+	destPos     BoardPosition
+	landPos     BoardPosition // Before any cheer/boo tile moved the stack on.
+	pushedBelow bool          // By a boo tile, under the stack at destPos.
+	tileOwner   Player        // Of the cheer/boo tile the stack landed on, if any.
 }
 
 type Game struct {
-	numPlayers int
-	// TODO: add player names.
-	camelTokens   [NumCamels]camel
-	boardSpaces   [BoardSize]boardSpace
-	ranking       [NumRacingCamels]Color
-	gameOver      bool
-	diePyramid    *DiePyramid
-	legMovesIndex int
-	legCamelMoves [NumMovesPerLeg]undoableMove
+	rules           *RuleSet
+	players         []playerState
+	currentPlayer   Player
+	camelTokens     [NumCamels]camel
+	boardSpaces     [MaxBoardSize]boardSpace
+	ranking         [NumRacingCamels]Color
+	gameOver        bool
+	diePyramid      *DiePyramid
+	legMovesIndex   int
+	legCamelMoves   [NumMovesPerLeg]undoableMove
+	legTicketsTaken [NumRacingCamels]int // Per camel, from the top of the stack.
+	winnerBets      []OverallBet
+	loserBets       []OverallBet
+	history         []historyEntry // Applied moves, oldest first.
+	redoMoves       []Move         // Undone moves, most recently undone last.
 }
 
 type GameStateInput struct {
 	Players []string // Player names, in order.
 	Camels  map[BoardPosition][]Color
-	Cheers  map[BoardPosition]string // To player name, for now ignored.
-	Boos    map[BoardPosition]string // To player name, for now ignored.
+	Cheers  map[BoardPosition]string // To player name, one tile per player.
+	Boos    map[BoardPosition]string // To player name, one tile per player.
+	// The number of leg tickets already taken from each camel's stack.
+	LegTicketsTaken map[Color]int
 	// The pyramid is only needed for simulations and for computations with
-	// some dice already rolled out.
+	// some dice already rolled out. It must be of the game's rule set.
 	DiePyramid *DiePyramid
+	// Defaults to the second edition.
+	Rules *RuleSet
 }
 
 type MoveType int
@@ -100,26 +121,74 @@ const (
 	MakePact
 )
 
+var moveTypeNames = []string{
+	"Roll",
+	"Cheer",
+	"Boo",
+	"Ticket",
+	"Winner",
+	"Loser",
+	"Pact",
+}
+
+func (t MoveType) String() string {
+	return moveTypeNames[t]
+}
+
 type Move struct {
 	Type   MoveType
 	Player Player
-	// Should be a union based on type. For now, only die rolls.
-	DieRoll DieRoll
+	// Should be a union based on type.
+	DieRoll DieRoll // RollDie.
+	// RollDie: roll the pyramid instead, and record the outcome in DieRoll.
+	RollPyramid bool
+	Color       Color         // BuyTicket, BetOnWinner, BetOnLoser.
+	Position    BoardPosition // PlaceCheer, PlaceBoo.
+	Partner     Player        // MakePact.
+}
+
+func (m Move) String() string {
+	switch m.Type {
+	case RollDie:
+		if m.DieRoll.Value > 0 {
+			return fmt.Sprintf("%s %s", m.Type, &m.DieRoll)
+		}
+	case PlaceCheer, PlaceBoo:
+		return fmt.Sprintf("%s %d", m.Type, m.Position+1)
+	case BuyTicket, BetOnWinner, BetOnLoser:
+		return fmt.Sprintf("%s %s", m.Type, m.Color)
+	case MakePact:
+		return fmt.Sprintf("%s %d", m.Type, m.Partner)
+	}
+	return m.Type.String()
 }
 
 func NewGameFromState(i *GameStateInput) (*Game, error) {
-	board := &Game{numPlayers: len(i.Players), diePyramid: i.DiePyramid}
+	board := &Game{rules: i.Rules, diePyramid: i.DiePyramid}
+	if board.rules == nil {
+		board.rules = SecondEdition
+	}
+	for _, name := range i.Players {
+		if _, err := board.playerByName(name); err == nil {
+			return nil, fmt.Errorf("player %s appears twice in input", name)
+		}
+		board.players = append(board.players, newPlayerState(name))
+	}
+	board.clearLegCamelMoves()
 	if board.diePyramid == nil {
-		board.diePyramid = NewDiePyramid(rand.New(rand.NewSource(*randomSeed)))
+		board.diePyramid = board.rules.NewDiePyramid(rand.New(rand.NewSource(*randomSeed)))
 	}
-	board.legMovesIndex = NumMovesPerLeg - board.diePyramid.RemainingRolls()
+	if err := board.rules.validate(); err != nil {
+		return nil, err
+	}
+	if board.diePyramid.rules != board.rules {
+		return nil, fmt.Errorf("die pyramid is of the %s rules, not the %s rules", board.diePyramid.rules.Name, board.rules.Name)
+	}
+	board.legMovesIndex = board.rules.RollsPerLeg - board.diePyramid.RemainingRolls()
 	for c := Green; c <= White; c++ {
-		board.camelTokens[c].Color = c
-		board.camelTokens[c].Position = -1
+		board.camelTokens[c].Position = NoPosition
 	}
-	board.camelTokens[White].OtherCrazy = &board.camelTokens[Black]
-	board.camelTokens[Black].OtherCrazy = &board.camelTokens[White]
-	for s := StartPosition; s <= FinishPosition; s++ {
+	for s := StartPosition; s <= board.FinishPosition(); s++ {
 		board.boardSpaces[s].Cheer = NoPlayer
 		board.boardSpaces[s].Boo = NoPlayer
 	}
@@ -127,106 +196,131 @@ func NewGameFromState(i *GameStateInput) (*Game, error) {
 		if len(colors) == 0 {
 			break
 		}
-		if p > FinishPosition || p < StartPosition {
+		if p > board.FinishPosition() || p < StartPosition {
 			return nil, fmt.Errorf("invalid board position: %d", p)
 		}
-		space := &board.boardSpaces[p]
-		var prev *camel
-		for i := 0; i < len(colors); i++ {
-			c := colors[i]
-			next := &board.camelTokens[c]
-			if prev == nil {
-				space.StackBottom = next
-			} else {
-				prev.Next = next
+		stack := &board.boardSpaces[p].Stack
+		for _, c := range colors {
+			if !board.rules.HasCamel(c) {
+				return nil, fmt.Errorf("%s camel is not in the %s rules", c, board.rules.Name)
 			}
-			if next.Position != -1 {
+			if board.camelTokens[c].Position != NoPosition {
 				return nil, fmt.Errorf("%s camel appears twice in input", c)
 			}
-			next.Position = p
-			next.Prev = prev
-			space.StackTop = next
-			prev = next
+			board.camelTokens[c] = camel{p, stack.Height}
+			stack.Camels[stack.Height] = c
+			stack.Height++
 		}
 	}
 	// Check that all camels are accounted for:
-	for c := Green; c <= White; c++ {
-		if board.camelTokens[c].Position == -1 {
+	for _, c := range board.rules.Camels {
+		if board.camelTokens[c].Position == NoPosition {
 			return nil, fmt.Errorf("%s camel is not placed on the board", c)
 		}
 	}
-	for p := range i.Cheers { // Players ignored for now.
-		if p > FinishPosition || p <= StartPosition {
-			return nil, fmt.Errorf("invalid board position: %d", p)
+	for p, name := range i.Cheers {
+		if err := board.checkTileSpace(PlaceCheer, p, NoPlayer); err != nil {
+			return nil, err
 		}
 		s := &board.boardSpaces[p]
-		if s.StackBottom != nil || s.Cheer != NoPlayer || s.Boo != NoPlayer {
-			return nil, fmt.Errorf("invalid cheer position %d, not empty", p)
+		owner, err := board.tileOwner(name, p)
+		if err != nil {
+			return nil, err
 		}
-		s.Cheer = 0 // just some player number for now.
+		s.Cheer = owner
 	}
-	for p := range i.Boos { // Players ignored for now.
-		if p > FinishPosition || p <= StartPosition {
-			return nil, fmt.Errorf("invalid board position: %d", p)
+	for p, name := range i.Boos {
+		if err := board.checkTileSpace(PlaceBoo, p, NoPlayer); err != nil {
+			return nil, err
 		}
 		s := &board.boardSpaces[p]
-		if s.StackBottom != nil || s.Cheer != NoPlayer || s.Boo != NoPlayer {
-			return nil, fmt.Errorf("invalid boo position %d, not empty", p)
+		owner, err := board.tileOwner(name, p)
+		if err != nil {
+			return nil, err
 		}
-		s.Boo = 0 // just some player number for now.
+		s.Boo = owner
+	}
+	for c, n := range i.LegTicketsTaken {
+		if err := checkRacingCamel(c); err != nil {
+			return nil, err
+		}
+		if n < 0 || n > len(board.rules.LegTicketValues) {
+			return nil, fmt.Errorf("invalid number of %s leg tickets taken: %d", c, n)
+		}
+		board.legTicketsTaken[c] = n
 	}
 	board.computeRanking()
 	return board, nil
 }
 
+// Makes the game a deep copy of the other game, except for the move history.
+// Only the pyramid's source of randomness is shared. The board is all values,
+// so only the players, bets and pyramid need copying on top of a plain copy.
+func (g *Game) copyFrom(o *Game) {
+	*g = *o
+	g.players = slices.Clone(o.players)
+	for i := range g.players {
+		g.players[i].LegTickets = slices.Clone(o.players[i].LegTickets)
+	}
+	g.winnerBets = slices.Clone(o.winnerBets)
+	g.loserBets = slices.Clone(o.loserBets)
+	g.diePyramid = &DiePyramid{r: o.diePyramid.r, rules: o.diePyramid.rules}
+	g.diePyramid.copyFrom(o.diePyramid)
+	g.history = nil
+	g.redoMoves = nil
+}
+
 func (g *Game) computeRankingGameOver() {
 	// Special cases: if the game is over because a crazy camel crossed
 	// over in the opposite direction, all the camels it was carrying (if any)
 	// are considered the least advanced.
 	// Similarly, when a normal camel crosses over, everyone in its stack are
 	// the most advanced (there can be no Boo token on space 0).
-	var specialBottom, specialTop *camel
 	move := &g.legCamelMoves[g.legMovesIndex-1]
-	if move.stackBottom.IsCrazy() {
-		if move.stackBottom.Next == nil {
-			g.computeRankingRegularCase()
-			return
-		}
+	stack := &g.boardSpaces[move.destPos].Stack
+	movedBottom := stack.Height - move.numCamels
+	if move.pushedBelow {
+		movedBottom = 0
+	}
+	moved := stack.Camels[movedBottom : movedBottom+move.numCamels]
+	var special [NumCamels]bool
+	for _, c := range moved {
+		special[c] = true
+	}
+	if move.camel.IsCrazy() {
 		curRank := Last
-		specialBottom = move.stackBottom.Next
-		for c := specialBottom; c != move.stackTop.Next; c = c.Next {
+		for _, c := range moved {
 			if !c.IsCrazy() {
-				specialTop = c
-				g.ranking[curRank] = c.Color
+				g.ranking[curRank] = c
 				curRank++
 			}
 		}
-		for i := StartPosition; i <= FinishPosition; i++ {
-			for c := g.boardSpaces[i].StackBottom; c != nil; c = c.Next {
-				if c == specialBottom {
-					c = specialTop
-				} else if !c.IsCrazy() {
-					g.ranking[curRank] = c.Color
+		if curRank == Last {
+			g.computeRankingRegularCase()
+			return
+		}
+		for i := StartPosition; i <= g.FinishPosition(); i++ {
+			s := &g.boardSpaces[i].Stack
+			for _, c := range s.Camels[:s.Height] {
+				if !special[c] && !c.IsCrazy() {
+					g.ranking[curRank] = c
 					curRank++
 				}
 			}
 		}
 	} else {
 		curRank := First
-		specialTop = move.stackTop
-		for c := specialTop; c != move.stackBottom.Prev; c = c.Prev {
-			if !c.IsCrazy() {
-				specialBottom = c
-				g.ranking[curRank] = c.Color
+		for j := len(moved) - 1; j >= 0; j-- {
+			if c := moved[j]; !c.IsCrazy() {
+				g.ranking[curRank] = c
 				curRank--
 			}
 		}
-		for i := FinishPosition; i >= StartPosition; i-- {
-			for c := g.boardSpaces[i].StackTop; c != nil; c = c.Prev {
-				if c == specialTop {
-					c = specialBottom
-				} else if !c.IsCrazy() {
-					g.ranking[curRank] = c.Color
+		for i := g.FinishPosition(); i >= StartPosition; i-- {
+			s := &g.boardSpaces[i].Stack
+			for j := s.Height - 1; j >= 0; j-- {
+				if c := s.Camels[j]; !special[c] && !c.IsCrazy() {
+					g.ranking[curRank] = c
 					curRank--
 				}
 			}
@@ -235,14 +329,20 @@ func (g *Game) computeRankingGameOver() {
 }
 
 func (g *Game) computeRankingRegularCase() {
-	curRank := Last
-	for i := StartPosition; i <= FinishPosition; i++ {
-		for c := g.boardSpaces[i].StackBottom; c != nil; c = c.Next {
-			if !c.IsCrazy() {
-				g.ranking[curRank] = c.Color
-				curRank++
+	// A racing camel's rank is the number of racing camels behind it.
+	var keys [NumRacingCamels]int
+	for c := range keys {
+		t := &g.camelTokens[c]
+		keys[c] = int(t.Position)<<3 | int(t.Height)
+	}
+	for c := range keys {
+		r := Last
+		for o := range keys {
+			if keys[o] < keys[c] {
+				r++
 			}
 		}
+		g.ranking[r] = Color(c)
 	}
 }
 
@@ -254,6 +354,19 @@ func (g *Game) computeRanking() {
 	}
 }
 
+// Returns the camels on the space, from the bottom up.
+func (g *Game) Stack(pos BoardPosition) []Color {
+	s := &g.boardSpaces[pos].Stack
+	return s.Camels[:s.Height]
+}
+
+// Returns where the camel is on the board, and its height in the stack there
+// from the bottom up. Camels not in the game have no position.
+func (g *Game) CamelPosition(c Color) (BoardPosition, int) {
+	t := &g.camelTokens[c]
+	return t.Position, int(t.Height)
+}
+
 func (g *Game) HasCheer(pos BoardPosition) bool {
 	return g.boardSpaces[pos].HasCheer()
 }
@@ -262,48 +375,47 @@ func (g *Game) HasBoo(pos BoardPosition) bool {
 	return g.boardSpaces[pos].HasBoo()
 }
 
-func (g *Game) moveStack(bottom *camel, top *camel, destPos BoardPosition, pushBelowStack bool) {
-	sourceSp := &g.boardSpaces[bottom.Position]
-	// Disconnect stack from source space, whether it is currently at the top or bottom:
-	if sourceSp.StackTop == top {
-		sourceSp.StackTop = bottom.Prev
-		if bottom.Prev == nil {
-			sourceSp.StackBottom = nil
-		} else {
-			bottom.Prev.Next = nil
-		}
-	} else {
-		sourceSp.StackBottom = top.Next
-		if top.Next == nil {
-			sourceSp.StackTop = nil
-		} else {
-			top.Next.Prev = nil
-			top.Next = nil
-		}
+// Returns the camel right on top of the given one, if any.
+func (g *Game) camelAbove(c Color) (Color, bool) {
+	t := &g.camelTokens[c]
+	s := &g.boardSpaces[t.Position].Stack
+	if t.Height+1 == s.Height {
+		return c, false
 	}
-	destSp := &g.boardSpaces[destPos]
-	prevBottom := destSp.StackBottom
-	bottom.Prev = nil
+	return s.Camels[t.Height+1], true
+}
+
+// Moves the n camels from the given height of the stack on srcPos to destPos,
+// either on top of the stack there or below it.
+func (g *Game) moveStack(srcPos BoardPosition, height, n int8, destPos BoardPosition, pushBelowStack bool) {
+	src := &g.boardSpaces[srcPos].Stack
+	var moved [NumCamels]Color
+	for i := int8(0); i < n; i++ {
+		moved[i] = src.Camels[height+i]
+	}
+	// Close the gap, and clear the unused part so that equal stacks compare equal.
+	src.Height -= n
+	for i := height; i < src.Height; i++ {
+		src.Camels[i] = src.Camels[i+n]
+		g.camelTokens[src.Camels[i]].Height = i
+	}
+	for i := src.Height; i < src.Height+n; i++ {
+		src.Camels[i] = 0
+	}
+	dest := &g.boardSpaces[destPos].Stack
+	bottom := dest.Height
 	if pushBelowStack {
-		destSp.StackBottom = bottom
-		top.Next = prevBottom
-		if prevBottom == nil {
-			destSp.StackTop = top
-		} else {
-			prevBottom.Prev = top
+		bottom = 0
+		for i := dest.Height - 1; i >= 0; i-- {
+			dest.Camels[i+n] = dest.Camels[i]
+			g.camelTokens[dest.Camels[i+n]].Height = i + n
 		}
-	} else {
-		if prevBottom == nil {
-			destSp.StackBottom = bottom
-		} else {
-			bottom.Prev = destSp.StackTop
-			destSp.StackTop.Next = bottom
-		}
-		destSp.StackTop = top
 	}
-	for ; bottom != top.Next; bottom = bottom.Next {
-		bottom.Position = destPos
+	for i := int8(0); i < n; i++ {
+		dest.Camels[bottom+i] = moved[i]
+		g.camelTokens[moved[i]] = camel{destPos, bottom + i}
 	}
+	dest.Height += n
 }
 
 // Applies move within the current leg of the race. This may
@@ -312,31 +424,44 @@ func (g *Game) moveStack(bottom *camel, top *camel, destPos BoardPosition, pushB
 func (g *Game) applyCamelMove(r *DieRoll) {
 	move := &g.legCamelMoves[g.legMovesIndex]
 	g.legMovesIndex++
-	c := &g.camelTokens[r.Color]
+	c := r.Color
 	moveDirection := 1
 	if c.IsCrazy() {
 		// Crazy camels have special rules.
 		moveDirection = -1
-		other := c.OtherCrazy
-		if c.Next == other || (c.Next == nil && other.Next != nil && other.Next != c) {
+		other := g.rules.otherCrazyCamel(c)
+		above, ok := g.camelAbove(c)
+		otherAbove, otherOk := g.camelAbove(other)
+		if ok && above == other || !ok && otherOk && otherAbove != c {
 			c = other
 		}
 	}
-	move.srcPos = c.Position
-	move.stackBottom = c
-	move.stackTop = g.boardSpaces[c.Position].StackTop
-	destPos := c.Position.Add(int(r.Value) * moveDirection)
+	t := g.camelTokens[c]
+	move.roll = *r
+	move.ranking = g.ranking
+	move.camel = c
+	move.srcPos = t.Position
+	move.numCamels = g.boardSpaces[t.Position].Stack.Height - t.Height
+	destPos := t.Position.Add(int(r.Value)*moveDirection, g.rules.BoardSize)
+	move.landPos = destPos
+	move.tileOwner = NoPlayer
 	if g.HasCheer(destPos) {
-		destPos = destPos.Add(moveDirection)
+		move.tileOwner = g.boardSpaces[destPos].Cheer
+		destPos = destPos.Add(moveDirection, g.rules.BoardSize)
 	}
-	g.gameOver = int(c.Position-destPos)*moveDirection > 0
+	g.gameOver = int(t.Position-destPos)*moveDirection > 0
 	pushBelowStack := false
 	if g.HasBoo(destPos) {
+		if move.tileOwner == NoPlayer {
+			move.tileOwner = g.boardSpaces[destPos].Boo
+		}
 		// The game is still over even if we are now below the finish line again.
-		destPos = destPos.Add(-moveDirection)
+		destPos = destPos.Add(-moveDirection, g.rules.BoardSize)
 		pushBelowStack = true
 	}
-	g.moveStack(c, move.stackTop, destPos, pushBelowStack)
+	move.destPos = destPos
+	move.pushedBelow = pushBelowStack
+	g.moveStack(t.Position, t.Height, move.numCamels, destPos, pushBelowStack)
 	g.computeRanking()
 }
 
@@ -345,24 +470,63 @@ func (g *Game) undoLastCamelMove() {
 	g.legMovesIndex--
 	move := &g.legCamelMoves[g.legMovesIndex]
 	// To undo, we always push to the top of the src stack.
-	g.moveStack(move.stackBottom, move.stackTop, move.srcPos, false)
-	g.computeRanking()
+	height := g.boardSpaces[move.destPos].Stack.Height - move.numCamels
+	if move.pushedBelow {
+		height = 0
+	}
+	g.moveStack(move.destPos, height, move.numCamels, move.srcPos, false)
+	g.ranking = move.ranking
+}
+
+// Computes all the possible outcomes for the current leg, counting both the
+// rankings and the camel landings on each space.
+func (g *Game) computeLegDistributions() (*RankingDistribution, *LandingDistribution) {
+	d, l := &RankingDistribution{}, &LandingDistribution{BoardSize: g.rules.BoardSize}
+	var rankingParts []*RankingDistribution
+	var landingParts []*LandingDistribution
+	firstMove := g.legMovesIndex
+	g.enumerateLegParallel(func(b *Game) func(weight int) {
+		rankingPart, landingPart := &RankingDistribution{}, &LandingDistribution{}
+		rankingParts = append(rankingParts, rankingPart)
+		landingParts = append(landingParts, landingPart)
+		return func(weight int) {
+			rankingPart.RecordWeightedRanking(&b.ranking, weight)
+			landingPart.TotalOutcomes += weight
+			for i := firstMove; i < b.legMovesIndex; i++ {
+				landingPart.Landings[b.legCamelMoves[i].landPos] += weight
+			}
+		}
+	})
+	for i := range rankingParts {
+		d.merge(rankingParts[i])
+		l.merge(landingParts[i])
+	}
+	return d, l
 }
 
-// Computes all the possible outcomes for the current leg.
-func (g *Game) ComputeLegRankingDistribution() *RankingDistribution {
-	// The remaining weight with N dice in the bag is 6^(N-1) * N!
-	remainingWeights := [6]int{1, 2 * 6, 6 * 36, 24 * 216, 120 * 1296}
+// Computes how many times camels land on each space during the current leg,
+// over all the possible outcomes.
+func (g *Game) ComputeLegLandingDistribution() *LandingDistribution {
+	_, l := g.computeLegDistributions()
+	return l
+}
+
+// Enumerates all the possible outcomes for the current leg. The visit function
+// is called on every outcome with the game in its final state, together with
+// the outcome's weight. The game is back in its original state when done.
+func (g *Game) enumerateLeg(visit func(weight int)) {
 	powersOf2 := [6]int{1, 2, 4, 8, 16, 32}
-	d := &RankingDistribution{}
-	if g.diePyramid.IsEmpty() {
-		// All dice were rolled: only the current board remains.
-		d.RecordRanking(&g.ranking)
-		return d
+	if g.gameOver || g.diePyramid.IsEmpty() {
+		// The race is over or all dice were rolled: only the current board
+		// remains.
+		visit(1)
+		return
 	}
 	colors := g.diePyramid.RemainingDice()
 	movesInLeg := g.diePyramid.RemainingRolls()
-	var used [6]bool
+	remainingWeights := g.diePyramid.remainingWeights()
+	grey := g.rules.GreyDie
+	var used [NumCamels]bool
 	colorIndices := [NumMovesPerLeg]int{-1, -1, -1, -1, -1}
 	var values [NumMovesPerLeg]RollValue
 	var roll DieRoll
@@ -372,10 +536,10 @@ func (g *Game) ComputeLegRankingDistribution() *RankingDistribution {
 
 			c := colors[colorIndices[curDie]]
 			values[curDie]++
-			if c == Black && values[curDie] > 6 || c != Black && values[curDie] > 3 {
+			if maxValue, _ := g.rules.dieValues(c); values[curDie] > maxValue {
 				// Try to find next unused color:
 				used[c] = false
-				for i := colorIndices[curDie] + 1; i <= movesInLeg; i++ {
+				for i := colorIndices[curDie] + 1; i < len(colors); i++ {
 					next := colors[i]
 					if !used[next] {
 						used[next] = true
@@ -401,55 +565,98 @@ func (g *Game) ComputeLegRankingDistribution() *RankingDistribution {
 			}
 			values[curDie] = 1
 		}
-		roll.Color = colors[colorIndices[curDie]]
-		roll.Value = values[curDie]
-		if roll.Value > 3 {
-			roll.Value -= 3
-			roll.Color = White
-		}
+		roll = g.rules.dieRoll(colors[colorIndices[curDie]], values[curDie])
 		g.applyCamelMove(&roll)
 		if g.gameOver || curDie == movesInLeg-1 {
 			weightIndex := curDie
-			if !used[Black] {
+			if grey == NoColor || !used[grey] {
 				weightIndex++
 			}
-			d.RecordWeightedRanking(&g.ranking, powersOf2[weightIndex]*remainingWeights[movesInLeg-curDie-1])
+			visit(powersOf2[weightIndex] * remainingWeights[movesInLeg-curDie-1])
 		} else {
 			curDie++
 		}
 	}
-	return d
 }
 
 // Simulates the current leg numSamples times. It is implemented in order to
 // test/validate the results of ComputeLegRankingDistribution.
 func (g *Game) SimulateLegRankingDistribution(numSamples int) *RankingDistribution {
 	d := &RankingDistribution{}
+	g.simulateLeg(numSamples, d)
+	return d
+}
+
+// Simulates the current leg numSamples times, recording the final rankings.
+func (g *Game) simulateLeg(numSamples int, d rankingRecorder) {
 	gameCopy := *g
+	var pyramidCopy DiePyramid
+	pyramidCopy.copyFrom(g.diePyramid)
 	for s := 0; s < numSamples; s++ {
+		g.diePyramid.shuffleRemaining()
 		for !g.LegOver() {
 			r, _ := g.diePyramid.Roll()
 			g.applyCamelMove(&r)
 		}
-		d.RecordRanking(&g.ranking)
-		g.diePyramid.Reset()
+		d.RecordWeightedRanking(&g.ranking, 1)
 		*g = gameCopy
+		g.diePyramid.copyFrom(&pyramidCopy)
 	}
+}
+
+// Simulates the rest of the race numSamples times, leg after leg, until the
+// game is over. The result is a distribution of the final rankings, so its
+// First and Last ranks are the overall winner and loser probabilities.
+func (g *Game) SimulateRaceDistribution(numSamples int) *RankingDistribution {
+	d := &RankingDistribution{}
+	g.simulateRace(numSamples, d)
 	return d
 }
 
+// Simulates the rest of the race numSamples times, recording the final
+// rankings.
+func (g *Game) simulateRace(numSamples int, d rankingRecorder) {
+	gameCopy := *g
+	var pyramidCopy DiePyramid
+	pyramidCopy.copyFrom(g.diePyramid)
+	for s := 0; s < numSamples; s++ {
+		g.diePyramid.shuffleRemaining()
+		for !g.gameOver {
+			if g.LegOver() {
+				g.removeTiles()
+				g.startNextLeg()
+			}
+			r, _ := g.diePyramid.Roll()
+			g.applyCamelMove(&r)
+		}
+		d.RecordWeightedRanking(&g.ranking, 1)
+		*g = gameCopy
+		g.diePyramid.copyFrom(&pyramidCopy)
+	}
+}
+
 func (g *Game) LegOver() bool {
-	return g.gameOver || g.legMovesIndex == NumMovesPerLeg
+	return g.gameOver || g.legMovesIndex == g.rules.RollsPerLeg
+}
+
+// Returns the rule set the game is played with.
+func (g *Game) Rules() *RuleSet {
+	return g.rules
+}
+
+// Returns the last space of the board, the one before the finish line.
+func (g *Game) FinishPosition() BoardPosition {
+	return BoardPosition(g.rules.BoardSize - 1)
 }
 
 // Pretty print the board to the user.
 func (g *Game) String() string {
 	var s strings.Builder
-	for p := StartPosition; p <= FinishPosition; p++ {
+	for p := StartPosition; p <= g.FinishPosition(); p++ {
 		fmt.Fprintf(&s, "%2d: ", p+1)
 		sp := &g.boardSpaces[p]
-		for c := sp.StackBottom; c != nil; c = c.Next {
-			fmt.Fprintf(&s, "%s ", c.Color)
+		for _, c := range sp.Stack.Camels[:sp.Stack.Height] {
+			fmt.Fprintf(&s, "%s ", c)
 		}
 		if sp.HasCheer() {
 			s.WriteString(colorPrinters[White](">>"))
@@ -467,5 +674,8 @@ func (g *Game) String() string {
 	for _, c := range g.ranking {
 		fmt.Fprintf(&s, "%s ", c)
 	}
+	for p := range g.players {
+		fmt.Fprintf(&s, "\n%s", &g.players[p])
+	}
 	return s.String()
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"strings"
 	"testing"
@@ -121,29 +122,86 @@ func TestNewGameFromStateFromInputFailure(t *testing.T) {
 		{
 			name: "non-empty cheer",
 			input: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1:  {Yellow, Green},
 					3:  {Red, Blue},
 					8:  {Purple},
 					15: {White, Black},
 				},
-				Cheers: map[BoardPosition]string{3: ""},
+				Cheers: map[BoardPosition]string{3: "Alice"},
 			},
 			wantError: "invalid cheer position 3, not empty",
 		},
 		{
 			name: "non-empty boo",
 			input: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1:  {Yellow, Green},
 					3:  {Red, Blue},
 					8:  {Purple},
 					15: {White, Black},
 				},
-				Boos: map[BoardPosition]string{3: ""},
+				Boos: map[BoardPosition]string{3: "Alice"},
 			},
 			wantError: "invalid boo position 3, not empty",
 		},
+		{
+			name: "unknown tile owner",
+			input: &GameStateInput{
+				Players: []string{"Alice"},
+				Camels: map[BoardPosition][]Color{
+					1:  {Yellow, Green},
+					3:  {Red, Blue},
+					8:  {Purple},
+					15: {White, Black},
+				},
+				Cheers: map[BoardPosition]string{5: "Bob"},
+			},
+			wantError: "unknown player: \"Bob\"",
+		},
+		{
+			name: "two tiles for a player",
+			input: &GameStateInput{
+				Players: []string{"Alice", "Bob"},
+				Camels: map[BoardPosition][]Color{
+					1:  {Yellow, Green},
+					3:  {Red, Blue},
+					8:  {Purple},
+					15: {White, Black},
+				},
+				Cheers: map[BoardPosition]string{5: "Alice"},
+				Boos:   map[BoardPosition]string{10: "Alice"},
+			},
+			wantError: "Alice has more than one spectator tile",
+		},
+		{
+			name: "doubled player",
+			input: &GameStateInput{
+				Players: []string{"Alice", "Bob", "Alice"},
+				Camels: map[BoardPosition][]Color{
+					1:  {Yellow, Green},
+					3:  {Red, Blue},
+					8:  {Purple},
+					15: {White, Black},
+				},
+			},
+			wantError: "player Alice appears twice in input",
+		},
+		{
+			name: "tickets taken for invalid camel",
+			input: &GameStateInput{
+				Camels: map[BoardPosition][]Color{
+					1:  {Yellow, Green},
+					3:  {Red, Blue},
+					8:  {Purple},
+					15: {White, Black},
+				},
+				LegTicketsTaken: map[Color]int{9: 1},
+			},
+			wantError: "invalid camel color: 9",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -157,36 +215,11 @@ func TestNewGameFromStateFromInputFailure(t *testing.T) {
 	}
 }
 
-// Compares camel tokens of different boards.
-func (c *camel) equals(o *camel) bool {
-	if c == nil {
-		return o == nil
-	}
-	if o == nil {
-		return false
-	}
-	return c.Color == o.Color && c.Position == o.Position && c.Next.shallowEquals(o.Next) && c.Prev.shallowEquals(o.Prev) && c.OtherCrazy.shallowEquals(o.OtherCrazy)
-}
-
-func (c *camel) shallowString() string {
-	if c == nil {
-		return "nil"
-	}
-	return c.Color.String()
-}
-
-func (c *camel) String() string {
-	if c == nil {
-		return "nil"
-	}
-	return fmt.Sprintf("{%s, %d, %s, %s, %s}", c.Color, c.Position, c.Prev.shallowString(), c.Next.shallowString(), c.OtherCrazy.shallowString())
-}
-
 func (s *boardSpace) String() string {
 	if s == nil {
 		return "nil"
 	}
-	return fmt.Sprintf("{ Cheer: %d Boo: %d StackBottom: %s StackTop: %s}\n", s.Cheer, s.Boo, s.StackBottom.shallowString(), s.StackTop.shallowString())
+	return fmt.Sprintf("{ Cheer: %d Boo: %d Stack: %v}\n", s.Cheer, s.Boo, s.Stack.Camels[:s.Stack.Height])
 }
 
 func (g *Game) fullString() string {
@@ -197,49 +230,9 @@ func (g *Game) fullString() string {
 	return s.String()
 }
 
-// This is needed to avoid infinite loops.
-func (c *camel) shallowEquals(o *camel) bool {
-	if c == nil {
-		return o == nil
-	}
-	if o == nil {
-		return false
-	}
-	return c.Color == o.Color
-}
-
-// Compares board spaces of different boards.
-func (s *boardSpace) equals(o *boardSpace) bool {
-	if s == nil {
-		return o == nil
-	}
-	if o == nil {
-		return false
-	}
-	return s.Cheer == o.Cheer && s.Boo == o.Boo && s.StackTop.equals(o.StackTop) && s.StackBottom.equals(o.StackBottom)
-}
-
 // Compares boards of different games.
 func (g *Game) equals(o *Game) bool {
-	//	fmt.Printf(">>>>>>>>>>>> g.fullString= %s\n", g.fullString())
-	//	fmt.Printf(">>>>>>>>>>>> o.fullString= %s\n", o.fullString())
-	for i := range g.camelTokens {
-		if !g.camelTokens[i].equals(&o.camelTokens[i]) {
-			//			fmt.Printf(">>>>>>>>>>>> camelTokens %d\n", i)
-			//			fmt.Printf(">>>>>>>>>>>> g.camelTokens[0]= %v\n", &g.camelTokens[i])
-			//			fmt.Printf(">>>>>>>>>>>> o.camelTokens[0]= %v\n", &o.camelTokens[i])
-			//			fmt.Printf(">>>>>>>>>>>> g.camelTokens[0].Prev= %v\n", g.camelTokens[i].Prev)
-			//			fmt.Printf(">>>>>>>>>>>> o.camelTokens[0].Prev= %v\n", o.camelTokens[i].Prev)
-			return false
-		}
-	}
-	for i := range g.boardSpaces {
-		if !g.boardSpaces[i].equals(&o.boardSpaces[i]) {
-			//			fmt.Printf(">>>>>>>>>>>> boardSpaces %d\n", i)
-			return false
-		}
-	}
-	return true
+	return g.camelTokens == o.camelTokens && g.boardSpaces == o.boardSpaces
 }
 
 func TestApplyCamelMove(t *testing.T) {
@@ -290,6 +283,7 @@ func TestApplyCamelMove(t *testing.T) {
 		},
 		{
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1:  {Yellow, Green},
 					3:  {Red, Blue},
@@ -297,11 +291,12 @@ func TestApplyCamelMove(t *testing.T) {
 					14: {White, Black},
 				},
 				Cheers: map[BoardPosition]string{
-					2: "",
+					2: "Alice",
 				},
 			},
 			dieRoll: &DieRoll{Green, 1},
 			wantState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1:  {Yellow},
 					3:  {Red, Blue, Green},
@@ -309,13 +304,14 @@ func TestApplyCamelMove(t *testing.T) {
 					14: {White, Black},
 				},
 				Cheers: map[BoardPosition]string{
-					2: "",
+					2: "Alice",
 				},
 			},
 			wantRanking: [NumRacingCamels]Color{Yellow, Red, Blue, Green, Purple},
 		},
 		{
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1:  {Yellow, Green},
 					3:  {Red, Blue},
@@ -323,11 +319,12 @@ func TestApplyCamelMove(t *testing.T) {
 					14: {White, Black},
 				},
 				Boos: map[BoardPosition]string{
-					4: "",
+					4: "Alice",
 				},
 			},
 			dieRoll: &DieRoll{Green, 3},
 			wantState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1:  {Yellow},
 					3:  {Green, Red, Blue},
@@ -335,13 +332,14 @@ func TestApplyCamelMove(t *testing.T) {
 					14: {White, Black},
 				},
 				Boos: map[BoardPosition]string{
-					4: "",
+					4: "Alice",
 				},
 			},
 			wantRanking: [NumRacingCamels]Color{Yellow, Green, Red, Blue, Purple},
 		},
 		{
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1:  {Yellow, Green},
 					3:  {Red, Blue},
@@ -349,42 +347,45 @@ func TestApplyCamelMove(t *testing.T) {
 					14: {White, Black},
 				},
 				Boos: map[BoardPosition]string{
-					4: "",
+					4: "Alice",
 				},
 			},
 			dieRoll: &DieRoll{Yellow, 3},
 			wantState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					3:  {Yellow, Green, Red, Blue},
 					8:  {Purple},
 					14: {White, Black},
 				},
 				Boos: map[BoardPosition]string{
-					4: "",
+					4: "Alice",
 				},
 			},
 			wantRanking: [NumRacingCamels]Color{Yellow, Green, Red, Blue, Purple},
 		},
 		{
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					9:  {Red, Blue},
 					10: {White, Purple},
 					14: {Yellow, Green, Black},
 				},
 				Cheers: map[BoardPosition]string{
-					15: "",
+					15: "Alice",
 				},
 			},
 			dieRoll: &DieRoll{Yellow, 1},
 			wantState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					9:  {Red, Blue},
 					10: {White, Purple},
 					0:  {Yellow, Green, Black},
 				},
 				Cheers: map[BoardPosition]string{
-					15: "",
+					15: "Alice",
 				},
 			},
 			wantRanking: [NumRacingCamels]Color{Red, Blue, Purple, Yellow, Green},
@@ -408,22 +409,24 @@ func TestApplyCamelMove(t *testing.T) {
 		},
 		{
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					0: {Red, Blue, White, Purple},
 					2: {Green, Black, Yellow},
 				},
 				Boos: map[BoardPosition]string{
-					1: "",
+					1: "Alice",
 				},
 			},
 			dieRoll: &DieRoll{Blue, 1},
 			wantState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					0: {Blue, White, Purple, Red},
 					2: {Green, Black, Yellow},
 				},
 				Boos: map[BoardPosition]string{
-					1: "",
+					1: "Alice",
 				},
 			},
 			wantRanking: [NumRacingCamels]Color{Blue, Purple, Red, Green, Yellow},
@@ -470,44 +473,48 @@ func TestApplyCamelMove(t *testing.T) {
 		},
 		{
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					0: {Red, Blue, White, Purple, Black, Yellow},
 					2: {Green},
 				},
 				Boos: map[BoardPosition]string{
-					15: "",
+					15: "Alice",
 				},
 			},
 			dieRoll: &DieRoll{White, 1},
 			wantState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					0: {White, Purple, Black, Yellow, Red, Blue},
 					2: {Green},
 				},
 				Boos: map[BoardPosition]string{
-					15: "",
+					15: "Alice",
 				},
 			},
 			wantRanking: [NumRacingCamels]Color{Purple, Yellow, Red, Blue, Green},
 		},
 		{
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					0: {Red, Blue, White, Purple, Black, Yellow},
 					2: {Green},
 				},
 				Boos: map[BoardPosition]string{
-					15: "",
+					15: "Alice",
 				},
 			},
 			dieRoll: &DieRoll{Black, 1},
 			wantState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					0: {Black, Yellow, Red, Blue, White, Purple},
 					2: {Green},
 				},
 				Boos: map[BoardPosition]string{
-					15: "",
+					15: "Alice",
 				},
 			},
 			wantRanking: [NumRacingCamels]Color{Yellow, Red, Blue, Purple, Green},
@@ -731,12 +738,13 @@ func TestComputeLegRankingDistribution(t *testing.T) {
 		{
 			desc: "all stacked, top three dice remain with Boo in front",
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1:  {Yellow, Green, Red, Blue, Purple},
 					13: {Black, White},
 				},
 				Boos: map[BoardPosition]string{
-					2: "",
+					2: "Alice",
 				},
 				DiePyramid: NewDiePyramidWithDice(r, []Color{Purple, Red, Blue}),
 			},
@@ -754,6 +762,7 @@ func TestComputeLegRankingDistribution(t *testing.T) {
 		{
 			desc: "two dice left - camels far apart",
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1:  {Yellow, Green, Red},
 					5:  {Blue},
@@ -761,7 +770,7 @@ func TestComputeLegRankingDistribution(t *testing.T) {
 					13: {Black, White},
 				},
 				Cheers: map[BoardPosition]string{
-					8: "",
+					8: "Alice",
 				},
 				DiePyramid: NewDiePyramidWithDice(r, []Color{Blue, Purple, Red}),
 			},
@@ -779,6 +788,7 @@ func TestComputeLegRankingDistribution(t *testing.T) {
 		{
 			desc: "two dice left - camels far apart - crazy camel - simple",
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1: {Yellow, Green, White, Red},
 					2: {Black},
@@ -786,7 +796,7 @@ func TestComputeLegRankingDistribution(t *testing.T) {
 					9: {Purple},
 				},
 				Cheers: map[BoardPosition]string{
-					8: "",
+					8: "Alice",
 				},
 				DiePyramid: NewDiePyramidWithDice(r, []Color{Blue, Purple, Black}),
 			},
@@ -804,13 +814,14 @@ func TestComputeLegRankingDistribution(t *testing.T) {
 		{
 			desc: "two dice left - camels far apart - crazy camel - complicated",
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					4:  {Yellow, Green, White, Red},
 					8:  {Black, Blue},
 					12: {Purple},
 				},
 				Cheers: map[BoardPosition]string{
-					11: "",
+					11: "Alice",
 				},
 				DiePyramid: NewDiePyramidWithDice(r, []Color{Blue, Purple, Black}),
 			},
@@ -828,13 +839,14 @@ func TestComputeLegRankingDistribution(t *testing.T) {
 		{
 			desc: "two dice left - camels far apart - crazy camel - complicated, game ends",
 			startState: &GameStateInput{
+				Players: []string{"Alice"},
 				Camels: map[BoardPosition][]Color{
 					1: {Yellow, Green, White, Red},
 					5: {Black, Blue},
 					9: {Purple},
 				},
 				Cheers: map[BoardPosition]string{
-					8: "",
+					8: "Alice",
 				},
 				DiePyramid: NewDiePyramidWithDice(r, []Color{Blue, Purple, Black}),
 			},
@@ -864,3 +876,79 @@ func TestComputeLegRankingDistribution(t *testing.T) {
 		})
 	}
 }
+
+func TestSimulateRaceDistribution(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			0:  {Yellow, Green, Red, Blue},
+			10: {White, Black},
+			14: {Purple},
+		},
+		DiePyramid: NewDiePyramidWithDice(r, []Color{Purple, Red, Blue}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			0:  {Yellow, Green, Red, Blue},
+			10: {White, Black},
+			14: {Purple},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := g.SimulateRaceDistribution(numSamples)
+	if d.TotalRankings != numSamples {
+		t.Errorf("want %d total rankings, got %d", numSamples, d.TotalRankings)
+	}
+	// Catching up with Purple before it crosses the finish line takes a
+	// very unlikely series of rolls.
+	if d.Rankings[Purple][First] < numSamples*9/10 {
+		t.Errorf("want Purple to win almost always, got:\n%s", d)
+	}
+	for c := Green; c < Black; c++ {
+		sum := 0
+		for _, n := range d.Rankings[c] {
+			sum += n
+		}
+		if sum != numSamples {
+			t.Errorf("want %d rankings for %s, got %d", numSamples, c, sum)
+		}
+	}
+	if !g.equals(cp) || g.legMovesIndex != 3 || g.diePyramid.RemainingRolls() != 2 {
+		t.Errorf("want board state restored after simulation:\n%s\nGot board state:\n%s\n", cp, g)
+	}
+}
+
+func TestSimulateRaceDistributionMidLeg(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// The race ends this leg whichever die is rolled first, so its
+	// distribution is the exact leg one: Green and Blue win half the time each.
+	g, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			0:  {Yellow, Red, Purple},
+			14: {Blue},
+			15: {Green},
+		},
+		DiePyramid: FirstEdition.NewDiePyramidWithDice(r, []Color{Green, Blue}),
+		Rules:      FirstEdition,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := g.ComputeLegRankingDistribution()
+	if want.Probability(Green, First) != 0.5 {
+		t.Fatalf("want Green to win half the time, got:\n%s", want)
+	}
+	got := g.SimulateRaceDistribution(numSamples)
+	for c := Green; c < Black; c++ {
+		for rank := Last; rank <= First; rank++ {
+			if math.Abs(got.Probability(c, rank)-want.Probability(c, rank)) > 0.1 {
+				t.Fatalf("SimulateRaceDistribution() got:\n%s\nwant:\n%s", got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import "errors"
+
+var (
+	ErrNothingToUndo = errors.New("no moves to undo")
+	ErrNothingToRedo = errors.New("no moves to redo")
+)
+
+// An applied move, with the game as it was before the move.
+type historyEntry struct {
+	move   Move
+	before *Game
+}
+
+// Returns all the moves applied to the game, oldest first.
+func (g *Game) History() []Move {
+	var result []Move
+	for _, e := range g.history {
+		result = append(result, e.move)
+	}
+	return result
+}
+
+// Applies the move and records it in the history.
+func (g *Game) applyRecordedMove(m Move) error {
+	before := &Game{}
+	before.copyFrom(g)
+	if err := g.applyMove(&m); err != nil {
+		// The move may have failed after ending a leg that was already over.
+		history, redoMoves := g.history, g.redoMoves
+		g.copyFrom(before)
+		g.history, g.redoMoves = history, redoMoves
+		return err
+	}
+	g.history = append(g.history, historyEntry{m, before})
+	return nil
+}
+
+// Undoes the last applied move, whatever it did: the game goes back to
+// exactly the state it was in before the move.
+func (g *Game) Undo() error {
+	if len(g.history) == 0 {
+		return ErrNothingToUndo
+	}
+	last := g.history[len(g.history)-1]
+	history := g.history[:len(g.history)-1]
+	redoMoves := append(g.redoMoves, last.move)
+	g.copyFrom(last.before)
+	g.history, g.redoMoves = history, redoMoves
+	return nil
+}
+
+// Reapplies the last undone move. Applying any new move discards the moves
+// that can be redone.
+func (g *Game) Redo() error {
+	if len(g.redoMoves) == 0 {
+		return ErrNothingToRedo
+	}
+	m := g.redoMoves[len(g.redoMoves)-1]
+	if err := g.applyRecordedMove(m); err != nil {
+		return err
+	}
+	g.redoMoves = g.redoMoves[:len(g.redoMoves)-1]
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+// Compares the boards and the players of different games.
+func (g *Game) sameState(o *Game) bool {
+	return g.equals(o) && g.String() == o.String() && g.currentPlayer == o.currentPlayer &&
+		g.legMovesIndex == o.legMovesIndex && g.legTicketsTaken == o.legTicketsTaken &&
+		len(g.winnerBets) == len(o.winnerBets) && len(g.loserBets) == len(o.loserBets)
+}
+
+func TestUndoRedo(t *testing.T) {
+	g := newTestGame(t, "Alice", "Bob")
+	moves := []Move{
+		{Type: BuyTicket, Player: 0, Color: Purple},
+		{Type: PlaceCheer, Player: 1, Position: 9},
+		{Type: RollDie, Player: 0, DieRoll: DieRoll{Purple, 1}},
+		{Type: BetOnWinner, Player: 1, Color: Purple},
+		{Type: RollDie, Player: 0, DieRoll: DieRoll{Green, 1}},
+		{Type: RollDie, Player: 1, DieRoll: DieRoll{Yellow, 1}},
+		{Type: RollDie, Player: 0, DieRoll: DieRoll{Red, 1}},
+		{Type: RollDie, Player: 1, DieRoll: DieRoll{White, 1}}, // Scores the leg.
+	}
+	var states []*Game
+	for _, m := range moves {
+		before := &Game{}
+		before.copyFrom(g)
+		states = append(states, before)
+		if err := g.ApplyMove(m); err != nil {
+			t.Fatalf("ApplyMove(%s) failed: %v", m, err)
+		}
+	}
+	final := &Game{}
+	final.copyFrom(g)
+	if got := g.History(); len(got) != len(moves) {
+		t.Fatalf("want history %v, got %v", moves, got)
+	}
+	for i := len(moves) - 1; i >= 0; i-- {
+		if err := g.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		if !g.sameState(states[i]) {
+			t.Errorf("want state after undoing %s:\n%s\nGot:\n%s\n", moves[i], states[i], g)
+		}
+	}
+	if err := g.Undo(); err != ErrNothingToUndo {
+		t.Errorf("want %v, got %v", ErrNothingToUndo, err)
+	}
+	for range moves {
+		if err := g.Redo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !g.sameState(final) {
+		t.Errorf("want state after redoing all moves:\n%s\nGot:\n%s\n", final, g)
+	}
+	// Fix a mis-entered roll: the moves after it cannot be redone anymore.
+	for range 4 {
+		if err := g.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.ApplyMove(Move{Type: RollDie, Player: 0, DieRoll: DieRoll{Green, 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Redo(); err != ErrNothingToRedo {
+		t.Errorf("want %v, got %v", ErrNothingToRedo, err)
+	}
+	if got := len(g.History()); got != 5 {
+		t.Errorf("want 5 moves in history, got %d", got)
+	}
+}
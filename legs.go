@@ -0,0 +1,39 @@
+package main
+
+// Ends the current leg: scores it, returns the spectator tiles to their
+// owners and the leg tickets to their stacks. Then either scores the race if
+// it is over, or starts the next leg.
+func (g *Game) endLeg() {
+	g.scoreLeg()
+	g.removeTiles()
+	for p := range g.players {
+		g.players[p].Tile = NoPosition
+	}
+	g.legTicketsTaken = [NumRacingCamels]int{}
+	if g.gameOver {
+		g.scoreGame()
+		return
+	}
+	g.startNextLeg()
+}
+
+// Starts the next leg of the race with a full pyramid.
+func (g *Game) startNextLeg() {
+	g.diePyramid.Refill()
+	g.legMovesIndex = 0
+	g.clearLegCamelMoves()
+}
+
+// Removes all the spectator tiles from the board.
+func (g *Game) removeTiles() {
+	for p := StartPosition; p <= g.FinishPosition(); p++ {
+		g.boardSpaces[p].Cheer = NoPlayer
+		g.boardSpaces[p].Boo = NoPlayer
+	}
+}
+
+func (g *Game) clearLegCamelMoves() {
+	for m := range g.legCamelMoves {
+		g.legCamelMoves[m] = undoableMove{tileOwner: NoPlayer}
+	}
+}
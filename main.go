@@ -27,6 +27,7 @@ func main() {
 
 	fmt.Printf("Seed: %d\n", *randomSeed)
 	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Player 1", "Player 2"},
 		Camels: map[BoardPosition][]Color{
 			0: {Blue, Green, Red, Yellow, Purple},
 			5: {White, Black},
@@ -48,4 +49,10 @@ func main() {
 	fmt.Println("Search time stats:")
 	fmt.Printf("Mean: %5.2f ms\n", mean/1000000)
 	fmt.Printf("Variance: %f ms squared \n", variance/float64(1000000*1000000))
+	d := g.ComputeLegRankingDistribution()
+	fmt.Printf("Leg ranking distribution:\n%s\n", d)
+	fmt.Println("Moves by expected value:")
+	for _, a := range Advise(g, 0) {
+		fmt.Printf("%+5.2f\t%s\n", a.EV, a.Move)
+	}
 }
@@ -0,0 +1,123 @@
+package main
+
+import "sync"
+
+// A canonical key of a state within a leg: the outcomes from it onwards only
+// depend on the camel stacks, the dice left in the pyramid and the number of
+// moves left in the leg. The spectator tiles don't move during a leg.
+type legStateKey struct {
+	camels    uint64 // The position and stack height of every camel, 8 bits each.
+	dice      uint8  // The dice left in the pyramid, one bit per color.
+	movesLeft int8
+}
+
+// A transposition table of the leg rankings distributions of the states seen
+// while computing a leg, safe for concurrent use.
+type legMemo struct {
+	mu    sync.Mutex
+	cache map[legStateKey]*RankingDistribution
+}
+
+func (m *legMemo) get(k legStateKey) (*RankingDistribution, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.cache[k]
+	return d, ok
+}
+
+func (m *legMemo) put(k legStateKey, d *RankingDistribution) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache[k] = d
+}
+
+func diceMask(dice []Color) uint8 {
+	var result uint8
+	for _, c := range dice {
+		result |= 1 << c
+	}
+	return result
+}
+
+func (g *Game) legStateKey(dice uint8, movesLeft int) legStateKey {
+	k := legStateKey{dice: dice, movesLeft: int8(movesLeft)}
+	for _, c := range g.rules.Camels {
+		t := &g.camelTokens[c]
+		k.camels |= uint64(int(t.Position)<<3|int(t.Height)) << (8 * int(c))
+	}
+	return k
+}
+
+// Computes all the possible outcomes for the current leg, with the same
+// weights as enumerateLeg. Subtrees reached by different die orders are only
+// computed once, and the subtrees of the first roll are computed concurrently.
+func (g *Game) ComputeLegRankingDistribution() *RankingDistribution {
+	d := &RankingDistribution{}
+	if g.gameOver || g.diePyramid.IsEmpty() {
+		// The leg is over: only the current board remains.
+		d.RecordWeightedRanking(&g.ranking, 1)
+		return d
+	}
+	weights := g.diePyramid.remainingWeights()
+	movesLeft := g.diePyramid.RemainingRolls() - 1
+	memo := &legMemo{cache: make(map[legStateKey]*RankingDistribution)}
+	branches := g.splitLeg()
+	parts := make([]*RankingDistribution, len(branches))
+	var wg sync.WaitGroup
+	for i, branch := range branches {
+		if branch.game.gameOver || movesLeft == 0 {
+			parts[i] = &RankingDistribution{}
+			parts[i].RecordWeightedRanking(&branch.game.ranking, branch.weight)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := branch.game
+			parts[i] = &RankingDistribution{}
+			parts[i].mergeScaled(b.legSubtreeDistribution(diceMask(b.diePyramid.RemainingDice()), movesLeft, &weights, memo), branch.scale)
+		}()
+	}
+	wg.Wait()
+	for _, part := range parts {
+		d.merge(part)
+	}
+	return d
+}
+
+// Returns the leg rankings distribution of the outcomes from the current board,
+// with the given dice left in the pyramid and moves left in the leg. The
+// pyramid itself is not used, and the game is back in its state when done.
+func (g *Game) legSubtreeDistribution(dice uint8, movesLeft int, weights *[NumMovesPerLeg]int, memo *legMemo) *RankingDistribution {
+	// Leaves and their parents are cheaper to compute than to look up.
+	useMemo := movesLeft >= 2
+	var key legStateKey
+	if useMemo {
+		key = g.legStateKey(dice, movesLeft)
+		if d, ok := memo.get(key); ok {
+			return d
+		}
+	}
+	d := &RankingDistribution{}
+	var roll DieRoll
+	for _, c := range g.rules.Dice {
+		if dice&(1<<c) == 0 {
+			continue
+		}
+		maxValue, scale := g.rules.dieValues(c)
+		for v := RollValue(1); v <= maxValue; v++ {
+			roll = g.rules.dieRoll(c, v)
+			g.applyCamelMove(&roll)
+			if g.gameOver || movesLeft == 1 {
+				d.RecordWeightedRanking(&g.ranking, scale*weights[movesLeft-1])
+			} else {
+				d.mergeScaled(g.legSubtreeDistribution(dice&^(1<<c), movesLeft-1, weights, memo), scale)
+			}
+			g.undoLastCamelMove()
+		}
+	}
+	if useMemo {
+		memo.put(key, d)
+	}
+	return d
+}
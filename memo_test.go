@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestLegStateKey(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	input := &GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			0: {Blue, Green},
+			2: {Red, Yellow, Purple},
+			9: {White, Black},
+		},
+		DiePyramid: NewDiePyramidWithDice(r, []Color{Green, Yellow, Red, Blue, Purple, Black}),
+	}
+	g1, err := NewGameFromState(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g2, err := NewGameFromState(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two die orders that don't interact reach the same state.
+	g1.applyCamelMove(&DieRoll{Purple, 3})
+	g1.applyCamelMove(&DieRoll{Black, 1})
+	g2.applyCamelMove(&DieRoll{Black, 1})
+	g2.applyCamelMove(&DieRoll{Purple, 3})
+	dice := diceMask([]Color{Green, Red, Blue})
+	if g1.legStateKey(dice, 2) != g2.legStateKey(dice, 2) {
+		t.Errorf("want the same key for:\n%s\nand:\n%s", g1, g2)
+	}
+	if g1.legStateKey(dice, 2) == g1.legStateKey(dice, 1) {
+		t.Error("want different keys for different moves left")
+	}
+	// The same rolls in another order stack the camels differently.
+	g1.undoLastCamelMove()
+	g1.undoLastCamelMove()
+	g1.applyCamelMove(&DieRoll{Green, 2})
+	g1.applyCamelMove(&DieRoll{Yellow, 1})
+	g2.undoLastCamelMove()
+	g2.undoLastCamelMove()
+	g2.applyCamelMove(&DieRoll{Yellow, 1})
+	g2.applyCamelMove(&DieRoll{Green, 2})
+	if g1.legStateKey(dice, 2) == g2.legStateKey(dice, 2) {
+		t.Errorf("want different keys for:\n%s\nand:\n%s", g1, g2)
+	}
+}
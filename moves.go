@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrGameOver = errors.New("game is over")
+	ErrLegOver  = errors.New("leg is over")
+)
+
+// Returns the player whose turn it is.
+func (g *Game) CurrentPlayer() Player {
+	return g.currentPlayer
+}
+
+// Applies a move of the current player, and passes the turn to the next one.
+// Rolls that end the leg also score it and start the next one, or score the
+// race when it is over. A game set up with no rolls left in the leg ends it
+// before the move. Illegal moves return an error and leave the game unchanged.
+// The move is recorded in the history, and can be undone.
+func (g *Game) ApplyMove(m Move) error {
+	if err := g.applyRecordedMove(m); err != nil {
+		return err
+	}
+	g.redoMoves = nil
+	return nil
+}
+
+func (g *Game) applyMove(m *Move) error {
+	g.endFinishedLeg()
+	if err := g.checkTurn(m.Player); err != nil {
+		return err
+	}
+	var err error
+	switch m.Type {
+	case RollDie:
+		err = g.rollDie(m)
+	case PlaceCheer, PlaceBoo:
+		err = g.placeTile(m.Type, m.Player, m.Position)
+	case BuyTicket:
+		err = g.buyLegTicket(m.Player, m.Color)
+	case BetOnWinner:
+		err = g.betOnWinner(m.Player, m.Color)
+	case BetOnLoser:
+		err = g.betOnLoser(m.Player, m.Color)
+	case MakePact:
+		err = g.makePact(m.Player, m.Partner)
+	default:
+		err = fmt.Errorf("unknown move type: %d", m.Type)
+	}
+	if err != nil {
+		return err
+	}
+	g.currentPlayer = (m.Player + 1) % Player(len(g.players))
+	return nil
+}
+
+// Ends the leg if it is over but the race is not, as in a game set up with no
+// rolls left in the leg.
+func (g *Game) endFinishedLeg() {
+	if !g.gameOver && g.LegOver() {
+		g.endLeg()
+	}
+}
+
+// Checks that it is the player's turn in a game that is still going.
+func (g *Game) checkTurn(p Player) error {
+	if g.gameOver {
+		return ErrGameOver
+	}
+	if g.LegOver() {
+		return ErrLegOver
+	}
+	if p < 0 || int(p) >= len(g.players) {
+		return fmt.Errorf("invalid player: %d", p)
+	}
+	if p != g.currentPlayer {
+		return fmt.Errorf("it is %s's turn, not %s's", g.PlayerName(g.currentPlayer), g.PlayerName(p))
+	}
+	return nil
+}
+
+// Applies a die roll from the pyramid, and ends the leg if it is over. A move
+// rolling the pyramid is turned into the roll it came out with.
+func (g *Game) rollDie(m *Move) error {
+	if m.RollPyramid {
+		roll, err := g.diePyramid.Roll()
+		if err != nil {
+			return err
+		}
+		m.DieRoll, m.RollPyramid = roll, false
+	} else if err := g.takeDie(&m.DieRoll); err != nil {
+		return err
+	}
+	g.takePyramidTicket(m.Player)
+	g.applyCamelMove(&m.DieRoll)
+	if g.LegOver() {
+		g.endLeg()
+	}
+	return nil
+}
+
+// Takes the die of a roll that happened at the table out of the pyramid.
+func (g *Game) takeDie(r *DieRoll) error {
+	if r.Color < Green || r.Color > White {
+		return fmt.Errorf("invalid die color: %d", r.Color)
+	}
+	if r.Value < 1 || r.Value > 3 {
+		return fmt.Errorf("invalid die value: %d", r.Value)
+	}
+	return g.diePyramid.Take(r.Color)
+}
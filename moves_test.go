@@ -0,0 +1,311 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestGame(t *testing.T, players ...string) *Game {
+	t.Helper()
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Players: players,
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green},
+			3:  {Red, Blue},
+			8:  {Purple},
+			14: {White, Black},
+		},
+		DiePyramid: NewDiePyramid(r),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestApplyMoveFailure(t *testing.T) {
+	testCases := []struct {
+		name      string
+		moves     []Move
+		wantError string
+	}{
+		{
+			name:      "wrong turn",
+			moves:     []Move{{Type: RollDie, Player: 1, DieRoll: DieRoll{Green, 1}}},
+			wantError: "it is Alice's turn, not Bob's",
+		},
+		{
+			name:      "invalid player",
+			moves:     []Move{{Type: BuyTicket, Player: 3, Color: Green}},
+			wantError: "invalid player: 3",
+		},
+		{
+			name:      "invalid die value",
+			moves:     []Move{{Type: RollDie, Player: 0, DieRoll: DieRoll{Green, 4}}},
+			wantError: "invalid die value: 4",
+		},
+		{
+			name:      "die value missing",
+			moves:     []Move{{Type: RollDie, Player: 0, DieRoll: DieRoll{Blue, 0}}},
+			wantError: "invalid die value: 0",
+		},
+		{
+			name:      "die roll missing",
+			moves:     []Move{{Type: RollDie, Player: 0}},
+			wantError: "invalid die value: 0",
+		},
+		{
+			name: "die already rolled",
+			moves: []Move{
+				{Type: RollDie, Player: 0, DieRoll: DieRoll{Black, 1}},
+				{Type: RollDie, Player: 1, DieRoll: DieRoll{White, 1}},
+			},
+			wantError: "die is not in the pyramid",
+		},
+		{
+			name: "no tickets left",
+			moves: []Move{
+				{Type: BuyTicket, Player: 0, Color: Red},
+				{Type: BuyTicket, Player: 1, Color: Red},
+				{Type: BuyTicket, Player: 2, Color: Red},
+				{Type: BuyTicket, Player: 0, Color: Red},
+				{Type: BuyTicket, Player: 1, Color: Red},
+			},
+			wantError: "no leg tickets left",
+		},
+		{
+			name:      "ticket for crazy camel",
+			moves:     []Move{{Type: BuyTicket, Player: 0, Color: White}},
+			wantError: "is not a racing camel",
+		},
+		{
+			name:      "ticket for invalid camel",
+			moves:     []Move{{Type: BuyTicket, Player: 0, Color: 9}},
+			wantError: "invalid camel color: 9",
+		},
+		{
+			name:      "bet on invalid camel",
+			moves:     []Move{{Type: BetOnWinner, Player: 0, Color: NoColor}},
+			wantError: "invalid camel color: -1",
+		},
+		{
+			name:      "bet on crazy camel",
+			moves:     []Move{{Type: BetOnLoser, Player: 0, Color: Black}},
+			wantError: "is not a racing camel",
+		},
+		{
+			name:      "tile on camels",
+			moves:     []Move{{Type: PlaceCheer, Player: 0, Position: 8}},
+			wantError: "invalid cheer position 8, not empty",
+		},
+		{
+			name:      "tile on start",
+			moves:     []Move{{Type: PlaceBoo, Player: 0, Position: StartPosition}},
+			wantError: "invalid board position: 0",
+		},
+		{
+			name: "tile on tile",
+			moves: []Move{
+				{Type: PlaceBoo, Player: 0, Position: 10},
+				{Type: PlaceCheer, Player: 1, Position: 10},
+			},
+			wantError: "invalid cheer position 10, not empty",
+		},
+		{
+			name: "tile next to tile",
+			moves: []Move{
+				{Type: PlaceBoo, Player: 0, Position: 10},
+				{Type: PlaceCheer, Player: 1, Position: 11},
+			},
+			wantError: "invalid cheer position 11, next to another spectator tile",
+		},
+		{
+			name: "finish card used",
+			moves: []Move{
+				{Type: BetOnWinner, Player: 0, Color: Purple},
+				{Type: BuyTicket, Player: 1, Color: Red},
+				{Type: BuyTicket, Player: 2, Color: Red},
+				{Type: BetOnLoser, Player: 0, Color: Purple},
+			},
+			wantError: "Alice has no",
+		},
+		{
+			name:      "pact with 3 players",
+			moves:     []Move{{Type: MakePact, Player: 0, Partner: 1}},
+			wantError: "pacts need at least 6 players",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newTestGame(t, "Alice", "Bob", "Carol")
+			last := len(tc.moves) - 1
+			for _, m := range tc.moves[:last] {
+				if err := g.ApplyMove(m); err != nil {
+					t.Fatalf("ApplyMove(%s) failed: %v", m, err)
+				}
+			}
+			err := g.ApplyMove(tc.moves[last])
+			if err == nil {
+				t.Errorf("wanted error with %s, got:\n%s", tc.wantError, g)
+			} else if !strings.Contains(err.Error(), tc.wantError) {
+				t.Errorf("wanted error with %s, got:\n%s", tc.wantError, err)
+			}
+			if g.CurrentPlayer() != Player(last%3) {
+				t.Errorf("want turn to stay with player %d, got %d", last%3, g.CurrentPlayer())
+			}
+		})
+	}
+}
+
+func TestApplyMoveEndsLeg(t *testing.T) {
+	g := newTestGame(t, "Alice", "Bob")
+	for _, m := range []Move{
+		{Type: BuyTicket, Player: 0, Color: Purple},
+		{Type: PlaceCheer, Player: 1, Position: 9},
+		{Type: RollDie, Player: 0, DieRoll: DieRoll{Purple, 1}}, // Lands on Bob's cheer.
+		{Type: BetOnWinner, Player: 1, Color: Purple},
+		{Type: RollDie, Player: 0, DieRoll: DieRoll{Green, 1}},
+		{Type: RollDie, Player: 1, DieRoll: DieRoll{Yellow, 1}},
+		{Type: RollDie, Player: 0, DieRoll: DieRoll{Red, 1}},
+		{Type: RollDie, Player: 1, DieRoll: DieRoll{White, 1}},
+	} {
+		if err := g.ApplyMove(m); err != nil {
+			t.Fatalf("ApplyMove(%s) failed: %v", m, err)
+		}
+	}
+	if g.LegOver() || g.legMovesIndex != 0 || g.diePyramid.RemainingRolls() != NumMovesPerLeg {
+		t.Errorf("want next leg started, got:\n%s", g)
+	}
+	if g.HasCheer(9) || g.SpectatorTile(1) != NoPosition {
+		t.Error("want spectator tiles returned")
+	}
+	if ticket, ok := g.TopLegTicket(Purple); !ok || ticket.Value != SecondEdition.LegTicketValues[0] {
+		t.Error("want leg tickets returned")
+	}
+	if err := g.ApplyMove(Move{Type: RollDie, Player: 0, DieRoll: DieRoll{Red, 2}}); err != nil {
+		t.Errorf("want next leg playable, got: %v", err)
+	}
+	wantCoins := []int{
+		3 + 5 + 3, // Purple ticket and three rolls.
+		3 + 1 + 2, // A cheer landing and two rolls.
+	}
+	for p, want := range wantCoins {
+		if got := g.Coins(Player(p)); got != want {
+			t.Errorf("want %s to have %d coins, got %d", g.PlayerName(Player(p)), want, got)
+		}
+	}
+}
+
+func TestApplyMoveEndsRace(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob"},
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green},
+			3:  {Red, Blue},
+			8:  {White, Black},
+			15: {Purple},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []Move{
+		{Type: BetOnWinner, Player: 0, Color: Purple},
+		{Type: BuyTicket, Player: 1, Color: Purple},
+		{Type: RollDie, Player: 0, DieRoll: DieRoll{Purple, 1}},
+	} {
+		if err := g.ApplyMove(m); err != nil {
+			t.Fatalf("ApplyMove(%s) failed: %v", m, err)
+		}
+	}
+	wantCoins := []int{
+		3 + 1 + 8, // A roll and the first winner bet.
+		3 + 5,     // The Purple ticket.
+	}
+	for p, want := range wantCoins {
+		if got := g.Coins(Player(p)); got != want {
+			t.Errorf("want %s to have %d coins, got %d", g.PlayerName(Player(p)), want, got)
+		}
+	}
+	if err := g.ApplyMove(Move{Type: RollDie, Player: 1, DieRoll: DieRoll{Red, 1}}); err != ErrGameOver {
+		t.Errorf("want %v, got %v", ErrGameOver, err)
+	}
+}
+
+func TestApplyMoveRollsPyramid(t *testing.T) {
+	g := newTestGame(t, "Alice", "Bob")
+	for i := 0; i < NumMovesPerLeg-1; i++ {
+		if err := g.ApplyMove(Move{Type: RollDie, Player: g.CurrentPlayer(), RollPyramid: true}); err != nil {
+			t.Fatalf("roll %d failed: %v", i+1, err)
+		}
+	}
+	rolled := make(map[Color]bool)
+	for _, m := range g.History() {
+		c := m.DieRoll.Color
+		if c == White {
+			c = Black
+		}
+		if m.RollPyramid || m.DieRoll.Value < 1 || m.DieRoll.Value > 3 || rolled[c] {
+			t.Errorf("want the roll outcomes in the history, got %v", g.History())
+		}
+		rolled[c] = true
+	}
+	if g.diePyramid.RemainingRolls() != 1 {
+		t.Errorf("want 1 roll left, got %d", g.diePyramid.RemainingRolls())
+	}
+	// Redoing a roll replays its outcome.
+	before := g.History()
+	want := g.String()
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.String(); got != want || g.History()[len(before)-1] != before[len(before)-1] {
+		t.Errorf("want redone roll:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestApplyMoveAfterLegOver(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob"},
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green},
+			3:  {Red, Blue},
+			8:  {Purple},
+			14: {White, Black},
+		},
+		Cheers:          map[BoardPosition]string{9: "Bob"},
+		LegTicketsTaken: map[Color]int{Purple: 1},
+		DiePyramid:      NewDiePyramidWithDice(r, []Color{Green}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.LegOver() {
+		t.Fatalf("want the leg over, got:\n%s", g)
+	}
+	if err := g.ApplyMove(Move{Type: PlaceCheer, Player: 0, Position: 20}); err == nil {
+		t.Fatal("want error placing a tile off the board")
+	}
+	if !g.LegOver() || !g.HasCheer(9) {
+		t.Errorf("want the game unchanged by an illegal move, got:\n%s", g)
+	}
+	if err := g.ApplyMove(Move{Type: BuyTicket, Player: 0, Color: Purple}); err != nil {
+		t.Fatalf("want the next leg playable, got: %v", err)
+	}
+	if g.LegOver() || g.diePyramid.RemainingRolls() != NumMovesPerLeg || g.HasCheer(9) {
+		t.Errorf("want next leg started, got:\n%s", g)
+	}
+	if tickets := g.LegTickets(0); len(tickets) != 1 || tickets[0].Value != SecondEdition.LegTicketValues[0] {
+		t.Errorf("want the top purple ticket of the new leg, got %v", tickets)
+	}
+	if err := g.Undo(); err != nil || !g.LegOver() {
+		t.Errorf("want undo back to the end of the leg, got %v:\n%s", err, g)
+	}
+}
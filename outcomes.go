@@ -0,0 +1,129 @@
+package main
+
+import "sort"
+
+// A camel move of a leg outcome.
+type LegMove struct {
+	Roll DieRoll
+	// The camel at the bottom of the moved stack. A crazy camel roll can move
+	// the other crazy camel.
+	Camel Color
+	// Where the die roll took the stack, before any cheer/boo tile moved it.
+	Landing BoardPosition
+	// Where the stack ended up.
+	Position BoardPosition
+	// The owner of the cheer/boo tile on the landing space, if any.
+	TileOwner Player
+	// Whether the stack was pushed below the stack at its position by a boo
+	// tile.
+	PushedBelow bool
+}
+
+// A possible outcome of the rest of the leg.
+type LegOutcome struct {
+	// The camel moves of the rest of the leg, in order.
+	Moves    []LegMove
+	Ranking  [NumRacingCamels]Color
+	GameOver bool
+	// The game in its final state, for looking at the board.
+	Game *Game
+}
+
+// Enumerates all the possible outcomes for the current leg, with the same
+// weights as ComputeLegRankingDistribution. If the race is already over, the
+// only outcome is the current board, with no moves. The outcome is only valid during
+// the visit, and the game must not be changed by it. The game is back in its
+// original state when done.
+func (g *Game) EnumerateLegOutcomes(visit func(o *LegOutcome, weight int)) {
+	firstMove := g.legMovesIndex
+	o := &LegOutcome{Moves: make([]LegMove, 0, NumMovesPerLeg), Game: g}
+	g.enumerateLeg(func(weight int) {
+		o.Moves = o.Moves[:0]
+		for _, m := range g.legCamelMoves[firstMove:g.legMovesIndex] {
+			o.Moves = append(o.Moves, LegMove{m.roll, m.camel, m.landPos, m.destPos, m.tileOwner, m.pushedBelow})
+		}
+		o.Ranking = g.ranking
+		o.GameOver = g.gameOver
+		visit(o, weight)
+	})
+}
+
+// Computes on which space and at which height each camel ends up, over all the
+// possible outcomes of the current leg.
+func (g *Game) ComputeLegPositionDistribution() *PositionDistribution {
+	d := &PositionDistribution{BoardSize: g.rules.BoardSize}
+	g.EnumerateLegOutcomes(func(o *LegOutcome, weight int) {
+		d.TotalOutcomes += weight
+		for _, c := range g.rules.Camels {
+			p, h := o.Game.CamelPosition(c)
+			d.Outcomes[c][p][h] += weight
+		}
+	})
+	return d
+}
+
+// Computes the joint distribution of the first and second camels over all the
+// possible outcomes of the current leg.
+func (g *Game) ComputeLegPairDistribution() *PairDistribution {
+	d := &PairDistribution{}
+	g.EnumerateLegOutcomes(func(o *LegOutcome, weight int) {
+		d.RecordWeightedRanking(&o.Ranking, weight)
+	})
+	return d
+}
+
+// Simulates the current leg numSamples times, counting the first and second
+// camels.
+func (g *Game) SimulateLegPairDistribution(numSamples int) *PairDistribution {
+	d := &PairDistribution{}
+	g.simulateLeg(numSamples, d)
+	return d
+}
+
+// Computes how likely the race is to end during the current leg, and how. A
+// race that is already over ends with certainty, the way it did.
+func (g *Game) ComputeLegRaceEndDistribution() *RaceEndDistribution {
+	d := &RaceEndDistribution{}
+	g.EnumerateLegOutcomes(func(o *LegOutcome, weight int) {
+		d.TotalOutcomes += weight
+		if !o.GameOver {
+			return
+		}
+		// The last move of the leg ended the race, whether or not it is one
+		// of the outcome's moves.
+		last := &o.Game.legCamelMoves[o.Game.legMovesIndex-1]
+		d.Crossings[last.camel] += weight
+		d.Winners[o.Ranking[First]] += weight
+	})
+	return d
+}
+
+// The leg rankings distribution given a possible next roll.
+type NextRollDistribution struct {
+	Roll         DieRoll
+	Probability  float64
+	Distribution *RankingDistribution
+}
+
+// Computes, for every possible next roll, its probability and the leg rankings
+// distribution given that roll. Grey die rolls come as Black and White rolls.
+// There are none once the leg is over.
+func (g *Game) ComputeNextRollDistributions() []NextRollDistribution {
+	if g.gameOver || g.diePyramid.IsEmpty() {
+		return nil
+	}
+	branches := g.splitLeg()
+	total := 0
+	for _, b := range branches {
+		total += b.weight
+	}
+	result := make([]NextRollDistribution, len(branches))
+	for i, b := range branches {
+		result[i] = NextRollDistribution{b.roll, float64(b.weight) / float64(total), b.game.ComputeLegRankingDistribution()}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		ri, rj := result[i].Roll, result[j].Roll
+		return ri.Color < rj.Color || ri.Color == rj.Color && ri.Value < rj.Value
+	})
+	return result
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestEnumerateLegOutcomes(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice"},
+		Camels: map[BoardPosition][]Color{
+			1:  {Red, Yellow, Purple},
+			8:  {Green},
+			13: {Blue},
+		},
+		Cheers:     map[BoardPosition]string{10: "Alice"},
+		DiePyramid: FirstEdition.NewDiePyramidWithDice(r, []Color{Green, Blue}),
+		Rules:      FirstEdition,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &RankingDistribution{}
+	gameOverWeight := 0
+	g.EnumerateLegOutcomes(func(o *LegOutcome, weight int) {
+		d.RecordWeightedRanking(&o.Ranking, weight)
+		last := o.Moves[len(o.Moves)-1]
+		if p, _ := o.Game.CamelPosition(last.Roll.Color); p != last.Position {
+			t.Errorf("want %s camel on %d after %v, got board:\n%s", last.Roll.Color, last.Position+1, o.Moves, o.Game)
+		}
+		for _, m := range o.Moves {
+			if m.Landing == 10 && (m.TileOwner != 0 || m.Position != 11) {
+				t.Errorf("want %v to hit the cheer on 11 and move on to 12", m)
+			}
+			if m.Landing != 10 && m.TileOwner != NoPlayer {
+				t.Errorf("want %v to hit no tile", m)
+			}
+		}
+		// Only blue crossing the finish line ends the race, and the leg with it.
+		crossed := last.Roll == DieRoll{Blue, 3}
+		if o.GameOver != crossed || len(o.Moves) != 2 && !crossed {
+			t.Errorf("want game over only after blue crosses the finish line, got %v game over: %t", o.Moves, o.GameOver)
+		}
+		if o.GameOver {
+			gameOverWeight += weight
+		}
+	})
+	if want := g.ComputeLegRankingDistribution(); *d != *want {
+		t.Errorf("want outcome rankings:\n%s\ngot:\n%s", want, d)
+	}
+	if gameOverWeight*3 != d.TotalRankings {
+		t.Errorf("want the race to end in 1/3 of the outcomes, got %d/%d", gameOverWeight, d.TotalRankings)
+	}
+}
+
+func TestComputeLegPositionDistribution(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			0: {Red, Yellow},
+			2: {Green},
+			4: {Purple},
+			9: {Blue},
+		},
+		DiePyramid: FirstEdition.NewDiePyramidWithDice(r, []Color{Green, Blue}),
+		Rules:      FirstEdition,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PositionDistribution{BoardSize: DefaultBoardSize, TotalOutcomes: 72}
+	want.Outcomes[Red][0][0] = 72
+	want.Outcomes[Yellow][0][1] = 72
+	want.Outcomes[Purple][4][0] = 72
+	// Green lands on top of purple with a 2.
+	want.Outcomes[Green][3][0], want.Outcomes[Green][4][1], want.Outcomes[Green][5][0] = 24, 24, 24
+	want.Outcomes[Blue][10][0], want.Outcomes[Blue][11][0], want.Outcomes[Blue][12][0] = 24, 24, 24
+	got := g.ComputeLegPositionDistribution()
+	if *got != *want {
+		t.Errorf("want position distribution:\n%s\ngot:\n%s", want, got)
+	}
+	for _, tc := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"green on top of purple", got.Probability(Green, 4, 1), float64(1) / 3},
+		{"green at the bottom of space 5", got.Probability(Green, 4, 0), 0},
+		{"green on space 5", got.PositionProbability(Green, 4), float64(1) / 3},
+		{"green at the bottom", got.HeightProbability(Green, 0), float64(2) / 3},
+		{"yellow on top of red", got.Probability(Yellow, 0, 1), 1},
+	} {
+		if math.Abs(tc.got-tc.want) > 1e-9 {
+			t.Errorf("want %s probability %f, got %f", tc.name, tc.want, tc.got)
+		}
+	}
+}
+
+func TestLegPairDistribution(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			1: {Red, Yellow, Purple},
+			8: {Green},
+			9: {Blue},
+		},
+		DiePyramid: FirstEdition.NewDiePyramidWithDice(r, []Color{Green, Blue}),
+		Rules:      FirstEdition,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PairDistribution{TotalRankings: 72}
+	want.Pairs[Green][Blue] = 28
+	want.Pairs[Blue][Green] = 44
+	if got := g.ComputeLegPairDistribution(); *got != *want {
+		t.Errorf("want pair distribution:\n%s\ngot:\n%s", want, got)
+	}
+	got := g.SimulateLegPairDistribution(numSamples)
+	if got.TotalRankings != numSamples || got.Pairs[Green][Blue]+got.Pairs[Blue][Green] != numSamples {
+		t.Errorf("want only green and blue to finish first and second, got:\n%s", got)
+	}
+	if p := got.Probability(Green, Blue); math.Abs(p-want.Probability(Green, Blue)) > 0.1 {
+		t.Errorf("want simulated green-blue probability close to %f, got %f", want.Probability(Green, Blue), p)
+	}
+}
+
+func TestComputeLegRaceEndDistribution(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	testCases := []struct {
+		desc  string
+		input *GameStateInput
+		want  *RaceEndDistribution
+	}{
+		{
+			desc: "blue crosses with a 3",
+			input: &GameStateInput{
+				Camels: map[BoardPosition][]Color{
+					1:  {Red, Yellow, Purple},
+					8:  {Green},
+					13: {Blue},
+				},
+				DiePyramid: FirstEdition.NewDiePyramidWithDice(r, []Color{Green, Blue}),
+				Rules:      FirstEdition,
+			},
+			want: &RaceEndDistribution{
+				TotalOutcomes: 72,
+				Crossings:     [NumCamels]int{Blue: 24},
+				Winners:       [NumRacingCamels]int{Blue: 24},
+			},
+		},
+		{
+			// Either grey die color moves black, carrying yellow.
+			desc: "black crosses backwards with a 2 or 3",
+			input: &GameStateInput{
+				Camels: map[BoardPosition][]Color{
+					0:  {Green},
+					1:  {Black, Yellow},
+					5:  {Red, Blue, Purple},
+					10: {White},
+				},
+				DiePyramid: NewDiePyramidWithDice(r, []Color{Green, Black}),
+			},
+			want: &RaceEndDistribution{
+				TotalOutcomes: 12,
+				Crossings:     [NumCamels]int{Black: 4},
+				Winners:       [NumRacingCamels]int{Purple: 4},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			g, err := NewGameFromState(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := g.ComputeLegRaceEndDistribution()
+			if *got != *tc.want {
+				t.Errorf("want race end distribution:\n%s\ngot:\n%s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestComputeNextRollDistributions(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			1: {Red, Yellow, Purple},
+			8: {Green},
+			9: {Blue},
+		},
+		DiePyramid: FirstEdition.NewDiePyramidWithDice(r, []Color{Green, Blue}),
+		Rules:      FirstEdition,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Green on top of blue with a 1 always wins, and so on.
+	wantGreenWins := map[DieRoll]float64{
+		{Green, 1}: 1, {Green, 2}: 0, {Green, 3}: 1.0 / 3,
+		{Blue, 1}: 2.0 / 3, {Blue, 2}: 1.0 / 3, {Blue, 3}: 0,
+	}
+	got := g.ComputeNextRollDistributions()
+	if len(got) != len(wantGreenWins) {
+		t.Fatalf("want %d next rolls, got %v", len(wantGreenWins), got)
+	}
+	total := 0.0
+	for i, n := range got {
+		if i > 0 && n.Roll.Color == got[i-1].Roll.Color && n.Roll.Value <= got[i-1].Roll.Value {
+			t.Errorf("want next rolls in order, got %s after %s", &n.Roll, &got[i-1].Roll)
+		}
+		if math.Abs(n.Probability-1.0/6) > 1e-9 {
+			t.Errorf("want probability 1/6 for %s, got %f", &n.Roll, n.Probability)
+		}
+		if p := n.Distribution.Probability(Green, First); math.Abs(p-wantGreenWins[n.Roll]) > 1e-9 {
+			t.Errorf("want green to win with probability %f after %s, got %f", wantGreenWins[n.Roll], &n.Roll, p)
+		}
+		total += n.Probability * n.Distribution.Probability(Green, First)
+	}
+	if want := g.ComputeLegRankingDistribution().Probability(Green, First); math.Abs(total-want) > 1e-9 {
+		t.Errorf("want next rolls to add up to probability %f of green winning, got %f", want, total)
+	}
+
+	// The grey die shows black or white.
+	g, err = NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			0:  {Green, Yellow, Red, Blue, Purple},
+			10: {Black},
+			12: {White},
+		},
+		DiePyramid: NewDiePyramidWithDice(r, []Color{Green, Black}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = g.ComputeNextRollDistributions()
+	if len(got) != 9 || got[3].Roll != (DieRoll{Black, 1}) || got[8].Roll != (DieRoll{White, 3}) {
+		t.Errorf("want green, black and white rolls, got %v", got)
+	}
+	if got[0].Probability != 1.0/6 || got[3].Probability != 1.0/12 {
+		t.Errorf("want probability 1/6 for a green roll and 1/12 for a black one, got %f and %f", got[0].Probability, got[3].Probability)
+	}
+}
+
+func TestEnumerateLegOutcomesGameOver(t *testing.T) {
+	for _, dice := range [][]Color{{Green, Blue, Red}, {Blue, Red}} {
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		g, err := NewGameFromState(&GameStateInput{
+			Players: []string{"Alice", "Bob"},
+			Camels: map[BoardPosition][]Color{
+				1:  {Red, Yellow, Purple},
+				8:  {Green},
+				13: {Blue},
+			},
+			DiePyramid: FirstEdition.NewDiePyramidWithDice(r, dice),
+			Rules:      FirstEdition,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.ApplyMove(Move{Type: RollDie, Player: 0, DieRoll: DieRoll{Blue, 3}}); err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		g.EnumerateLegOutcomes(func(o *LegOutcome, weight int) {
+			n++
+			if !o.GameOver || len(o.Moves) != 0 || weight != 1 || o.Ranking[First] != Blue {
+				t.Errorf("want the finished race as the only outcome, got %+v with weight %d", o, weight)
+			}
+		})
+		if n != 1 {
+			t.Errorf("want 1 outcome, got %d", n)
+		}
+		want := &RaceEndDistribution{
+			TotalOutcomes: 1,
+			Crossings:     [NumCamels]int{Blue: 1},
+			Winners:       [NumRacingCamels]int{Blue: 1},
+		}
+		if got := g.ComputeLegRaceEndDistribution(); *got != *want {
+			t.Errorf("want race end distribution:\n%s\ngot:\n%s", want, got)
+		}
+		if !g.gameOver {
+			t.Fatal("want the race to still be over")
+		}
+		if err := g.ApplyMove(Move{Type: BuyTicket, Player: 1, Color: Red}); err != ErrGameOver {
+			t.Errorf("want %v buying a ticket after the race, got %v", ErrGameOver, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import "fmt"
+
+// Pacts (partnerships) are only allowed in games with this many players or
+// more.
+const minPactPlayers = 6
+
+// Returns the player's partner for the current leg, or NoPlayer.
+func (g *Game) Partner(p Player) Player {
+	return g.players[p].Partner
+}
+
+// Checks that the player can make a pact with the partner: the game must
+// be big enough, and neither of them can be in a pact already.
+func (g *Game) checkPact(p, partner Player) error {
+	if !g.rules.Pacts {
+		return fmt.Errorf("pacts are not part of the %s rules", g.rules.Name)
+	}
+	if len(g.players) < minPactPlayers {
+		return fmt.Errorf("pacts need at least %d players, got %d", minPactPlayers, len(g.players))
+	}
+	if partner < 0 || int(partner) >= len(g.players) {
+		return fmt.Errorf("invalid partner: %d", partner)
+	}
+	if p == partner {
+		return fmt.Errorf("%s cannot make a pact with themselves", g.PlayerName(p))
+	}
+	for _, q := range []Player{p, partner} {
+		if g.players[q].Partner != NoPlayer {
+			return fmt.Errorf("%s is already in a pact with %s", g.PlayerName(q), g.PlayerName(g.players[q].Partner))
+		}
+	}
+	return nil
+}
+
+func (g *Game) makePact(p, partner Player) error {
+	if err := g.checkPact(p, partner); err != nil {
+		return err
+	}
+	g.players[p].Partner = partner
+	g.players[partner].Partner = p
+	return nil
+}
+
+// Returns what a pact collects from the partner's tickets at the end of the
+// leg: the payout of their best ticket, if it pays at all.
+func bestLegTicketPayout(tickets []LegTicket, ranks *[NumRacingCamels]Rank) int {
+	best := 0
+	for _, t := range tickets {
+		best = max(best, t.Payout(ranks[t.Color]))
+	}
+	return best
+}
+
+// Returns the expected coins the player would collect from a pact with each
+// of their possible partners, based on the partner's current leg tickets.
+func (g *Game) PactEVs(player Player) map[Player]float64 {
+	result := make(map[Player]float64)
+	var partners []Player
+	for q := range g.players {
+		if g.checkPact(player, Player(q)) == nil {
+			partners = append(partners, Player(q))
+		}
+	}
+	if len(partners) == 0 {
+		return result
+	}
+	collected := make([]int, len(partners))
+	total := 0
+	g.enumerateLeg(func(weight int) {
+		total += weight
+		ranks := ranksOf(&g.ranking)
+		for i, q := range partners {
+			collected[i] += weight * bestLegTicketPayout(g.players[q].LegTickets, &ranks)
+		}
+	})
+	for i, q := range partners {
+		result[q] = float64(collected[i]) / float64(total)
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestPacts(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob", "Carol", "Dave", "Erin", "Frank"},
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green},
+			3:  {Red, Blue},
+			8:  {Purple},
+			14: {White, Black},
+		},
+		// All the dice were rolled, so the ranking is final.
+		DiePyramid: NewDiePyramidWithDice(r, []Color{Purple}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []struct {
+		player Player
+		color  Color
+	}{
+		{1, Purple}, {1, Blue}, {2, Yellow}, {3, Red},
+	} {
+		if err := g.buyLegTicket(b.player, b.color); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantEVs := map[Player]float64{1: 5, 2: 0, 3: 0, 4: 0, 5: 0}
+	if got := g.PactEVs(0); len(got) != len(wantEVs) {
+		t.Errorf("want pact EVs %v, got %v", wantEVs, got)
+	} else {
+		for q, ev := range wantEVs {
+			if got[q] != ev {
+				t.Errorf("want pact EVs %v, got %v", wantEVs, got)
+			}
+		}
+	}
+	if err := g.makePact(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []struct {
+		player, partner Player
+	}{
+		{2, 2}, {2, 1}, {0, 3}, {2, 6},
+	} {
+		if err := g.makePact(p.player, p.partner); err == nil {
+			t.Errorf("want error making a pact between %d and %d", p.player, p.partner)
+		}
+	}
+	if err := g.makePact(2, 3); err != nil {
+		t.Fatal(err)
+	}
+	g.scoreLeg()
+	wantCoins := []int{
+		3 + 5,     // Bob's Purple ticket.
+		3 + 5 + 1, // Own tickets, nothing to collect from Alice.
+		3 - 1,     // Dave's Red ticket loses too, which costs nothing.
+		3 - 1,
+		3,
+		3,
+	}
+	for p, want := range wantCoins {
+		if got := g.Coins(Player(p)); got != want {
+			t.Errorf("want %s to have %d coins, got %d", g.PlayerName(Player(p)), want, got)
+		}
+		if g.Partner(Player(p)) != NoPlayer {
+			t.Errorf("want %s pact dissolved after the leg", g.PlayerName(Player(p)))
+		}
+	}
+}
+
+func TestPactsNeedSixPlayers(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob", "Carol", "Dave", "Erin"},
+		Camels: map[BoardPosition][]Color{
+			0: {Yellow, Green, Red, Blue, Purple, White, Black},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.makePact(0, 1); err == nil {
+		t.Error("want error making a pact with 5 players")
+	}
+	if evs := g.PactEVs(0); len(evs) != 0 {
+		t.Errorf("want no pact EVs with 5 players, got %v", evs)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import "sync"
+
+// A subtree of the current leg's outcomes, after one possible first roll.
+type legBranch struct {
+	roll DieRoll
+	game *Game // A copy of the game after the roll.
+	// The factor the subtree's weights are scaled by: the grey die has 6
+	// outcomes, so every other die counts each of its 3 values twice.
+	scale int
+	// The total weight of the subtree's outcomes, which is the weight of its
+	// only outcome if the roll ends the race.
+	weight int
+}
+
+// Splits the outcome tree of the current leg by the first die roll. The
+// pyramid must not be empty.
+func (g *Game) splitLeg() []legBranch {
+	var result []legBranch
+	remainingWeights := g.diePyramid.remainingWeights()
+	weight := remainingWeights[g.diePyramid.RemainingRolls()-1]
+	for _, c := range g.diePyramid.RemainingDice() {
+		maxValue, scale := g.rules.dieValues(c)
+		for v := RollValue(1); v <= maxValue; v++ {
+			b := &Game{}
+			b.copyFrom(g)
+			roll := g.rules.dieRoll(c, v)
+			b.diePyramid.Take(c)
+			b.applyCamelMove(&roll)
+			result = append(result, legBranch{roll, b, scale, scale * weight})
+		}
+	}
+	return result
+}
+
+// Enumerates all the possible outcomes for the current leg like enumerateLeg,
+// but splits the outcome tree by the first die roll and walks the subtrees
+// concurrently, each on its own copy of the game. The newVisit function is
+// called once per subtree with its game copy, only on the calling goroutine,
+// but the subtrees already started may be enumerating meanwhile. The visit
+// function it returns is only ever called from that subtree's goroutine. The
+// weights are the same as those of enumerateLeg, so merging the integer
+// results of the subtrees gives the same result regardless of their order.
+func (g *Game) enumerateLegParallel(newVisit func(b *Game) func(weight int)) {
+	if g.gameOver || g.diePyramid.IsEmpty() {
+		g.enumerateLeg(newVisit(g))
+		return
+	}
+	var wg sync.WaitGroup
+	for _, branch := range g.splitLeg() {
+		visit := newVisit(branch.game)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if branch.game.gameOver {
+				visit(branch.weight)
+				return
+			}
+			branch.game.enumerateLeg(func(weight int) {
+				visit(branch.scale * weight)
+			})
+		}()
+	}
+	wg.Wait()
+}
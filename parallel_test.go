@@ -0,0 +1,75 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestEnumerateLegParallel(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	testCases := []struct {
+		desc  string
+		input *GameStateInput
+	}{
+		{
+			desc: "fresh leg",
+			input: &GameStateInput{
+				Camels: map[BoardPosition][]Color{
+					0: {Blue, Green, Red, Yellow, Purple},
+					5: {White, Black},
+				},
+			},
+		},
+		{
+			desc: "race ending with tiles",
+			input: &GameStateInput{
+				Players: []string{"Alice", "Bob"},
+				Camels: map[BoardPosition][]Color{
+					10: {Blue, Green},
+					12: {Red, Yellow},
+					14: {Purple},
+					15: {Black},
+					1:  {White},
+				},
+				Cheers:     map[BoardPosition]string{13: "Alice"},
+				Boos:       map[BoardPosition]string{11: "Bob"},
+				DiePyramid: NewDiePyramidWithDice(r, []Color{Green, Purple, Black, Red}),
+			},
+		},
+		{
+			desc: "first edition",
+			input: &GameStateInput{
+				Camels: map[BoardPosition][]Color{
+					0: {Blue, Green},
+					2: {Red, Yellow, Purple},
+				},
+				Rules: FirstEdition,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			g, err := NewGameFromState(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &RankingDistribution{}
+			wantLandings := &LandingDistribution{BoardSize: g.rules.BoardSize}
+			firstMove := g.legMovesIndex
+			g.enumerateLeg(func(weight int) {
+				want.RecordWeightedRanking(&g.ranking, weight)
+				wantLandings.TotalOutcomes += weight
+				for i := firstMove; i < g.legMovesIndex; i++ {
+					wantLandings.Landings[g.legCamelMoves[i].landPos] += weight
+				}
+			})
+			if got := g.ComputeLegRankingDistribution(); *got != *want {
+				t.Errorf("ComputeLegRankingDistribution() got:\n%s\nwant:\n%s", got, want)
+			}
+			if _, got := g.computeLegDistributions(); *got != *wantLandings {
+				t.Errorf("computeLegDistributions() got landings:\n%s\nwant:\n%s", got, wantLandings)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import "fmt"
+
+// The coins each player starts the game with.
+const startingCoins = 3
+
+type playerState struct {
+	Name           string
+	Coins          int
+	LegTickets     []LegTicket
+	PyramidTickets int
+	// Finish cards already placed on the overall winner/loser stacks.
+	FinishCardsUsed [NumRacingCamels]bool
+	// Where the player's spectator tile is on the board, if anywhere.
+	Tile BoardPosition
+	// The player's partner for the current leg, if any.
+	Partner Player
+}
+
+func newPlayerState(name string) playerState {
+	return playerState{Name: name, Coins: startingCoins, Tile: NoPosition, Partner: NoPlayer}
+}
+
+func (g *Game) NumPlayers() int {
+	return len(g.players)
+}
+
+func (g *Game) PlayerName(p Player) string {
+	return g.players[p].Name
+}
+
+func (g *Game) Coins(p Player) int {
+	return g.players[p].Coins
+}
+
+func (g *Game) playerByName(name string) (Player, error) {
+	for p := range g.players {
+		if g.players[p].Name == name {
+			return Player(p), nil
+		}
+	}
+	return NoPlayer, fmt.Errorf("unknown player: %q", name)
+}
+
+// Returns the leg tickets the player is holding.
+func (g *Game) LegTickets(p Player) []LegTicket {
+	return g.players[p].LegTickets
+}
+
+func (g *Game) PyramidTickets(p Player) int {
+	return g.players[p].PyramidTickets
+}
+
+// Returns where the player's spectator tile is, or NoPosition if it is not on
+// the board.
+func (g *Game) SpectatorTile(p Player) BoardPosition {
+	return g.players[p].Tile
+}
+
+// Gives the top leg ticket of the camel's stack to the player.
+func (g *Game) buyLegTicket(p Player, c Color) error {
+	t, err := g.takeLegTicket(c)
+	if err != nil {
+		return err
+	}
+	g.players[p].LegTickets = append(g.players[p].LegTickets, t)
+	return nil
+}
+
+// Gives the player a pyramid ticket for rolling a die.
+func (g *Game) takePyramidTicket(p Player) {
+	g.players[p].PyramidTickets++
+}
+
+// Returns the rank of every racing camel in the ranking.
+func ranksOf(ranking *[NumRacingCamels]Color) [NumRacingCamels]Rank {
+	var ranks [NumRacingCamels]Rank
+	for r, c := range ranking {
+		ranks[c] = Rank(r)
+	}
+	return ranks
+}
+
+// Pays every player for their leg tickets, their partner's best leg ticket,
+// pyramid tickets and the camel landings on their spectator tile during the
+// leg, and returns the spent tickets and dissolves the pacts. Coins never go
+// below zero.
+func (g *Game) scoreLeg() {
+	earnings := make([]int, len(g.players))
+	ranks := ranksOf(&g.ranking)
+	for p := range g.players {
+		ps := &g.players[p]
+		for _, t := range ps.LegTickets {
+			earnings[p] += t.Payout(ranks[t.Color])
+		}
+		if ps.Partner != NoPlayer {
+			earnings[p] += bestLegTicketPayout(g.players[ps.Partner].LegTickets, &ranks)
+		}
+		earnings[p] += ps.PyramidTickets * pyramidTicketValue
+	}
+	for p := range g.players {
+		ps := &g.players[p]
+		ps.LegTickets = nil
+		ps.PyramidTickets = 0
+		ps.Partner = NoPlayer
+	}
+	for _, m := range g.legCamelMoves[:g.legMovesIndex] {
+		if m.tileOwner != NoPlayer {
+			earnings[m.tileOwner]++
+		}
+	}
+	for p, e := range earnings {
+		g.players[p].Coins = max(0, g.players[p].Coins+e)
+	}
+}
+
+func (ps *playerState) String() string {
+	return fmt.Sprintf("%s: %d coins, leg tickets %v, %d pyramid tickets", ps.Name, ps.Coins, ps.LegTickets, ps.PyramidTickets)
+}
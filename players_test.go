@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestScoreLeg(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob", "Carol"},
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green},
+			3:  {Red, Blue},
+			8:  {Purple},
+			14: {White, Black},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.boardSpaces[2].setTile(PlaceCheer, 1)
+	for _, b := range []struct {
+		player Player
+		color  Color
+	}{
+		{0, Purple}, {0, Purple}, {1, Green}, {2, Yellow}, {2, Red}, {2, Blue}, {2, Yellow},
+	} {
+		if err := g.buyLegTicket(b.player, b.color); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.takePyramidTicket(0)
+	// Green lands on Bob's cheer and moves on to the top of the Red/Blue stack.
+	g.applyCamelMove(&DieRoll{Green, 1})
+	g.scoreLeg()
+	wantCoins := []int{
+		3 + 5 + 3 + 1, // Purple wins, plus a pyramid ticket.
+		3 + 1 + 1,     // Green is second, plus a landing on the cheer.
+		0,             // Lost 4 coins, but only had 3.
+	}
+	for p, want := range wantCoins {
+		if got := g.Coins(Player(p)); got != want {
+			t.Errorf("want %s to have %d coins, got %d", g.PlayerName(Player(p)), want, got)
+		}
+		if len(g.LegTickets(Player(p))) != 0 || g.PyramidTickets(Player(p)) != 0 {
+			t.Errorf("want %s tickets returned, got:\n%s", g.PlayerName(Player(p)), g)
+		}
+	}
+}
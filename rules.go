@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A rule set holds what differs between the editions of the game.
+type RuleSet struct {
+	Name string
+	// All the camels in the race, racing camels first. Crazy camels, if any,
+	// move backwards.
+	Camels []Color
+	// The dice in a full pyramid.
+	Dice []Color
+	// The die that rolls the crazy camels, NoColor without them. Its values
+	// 1-3 move the first crazy camel, and 4-6 move the second one by 1-3.
+	GreyDie     Color
+	CrazyCamels [2]Color
+	// The number of dice rolled in a leg. The rest stay in the pyramid.
+	RollsPerLeg int
+	// The values of each camel's leg tickets, from the top of the stack down.
+	LegTicketValues []int
+	// Whether big games can be played with pacts.
+	Pacts bool
+	// The number of spaces on the track, the last one before the finish line.
+	// Homebrew variants play on longer or shorter tracks.
+	BoardSize int
+}
+
+// The second edition, with crazy camels rolled with the grey die, and pacts.
+var SecondEdition = &RuleSet{
+	Name:            "second edition",
+	Camels:          []Color{Green, Yellow, Red, Blue, Purple, Black, White},
+	Dice:            []Color{Green, Yellow, Red, Blue, Purple, Black},
+	GreyDie:         Black,
+	CrazyCamels:     [2]Color{Black, White},
+	RollsPerLeg:     5,
+	LegTicketValues: []int{5, 3, 2, 2},
+	Pacts:           true,
+	BoardSize:       DefaultBoardSize,
+}
+
+// The first edition, with five racing camels only. All the dice are rolled in
+// every leg, and there are only three leg tickets per camel. Its desert tiles
+// (oasis/mirage) move camels as the cheer/boo tiles do.
+var FirstEdition = &RuleSet{
+	Name:            "first edition",
+	Camels:          []Color{Green, Yellow, Red, Blue, Purple},
+	Dice:            []Color{Green, Yellow, Red, Blue, Purple},
+	GreyDie:         NoColor,
+	CrazyCamels:     [2]Color{NoColor, NoColor},
+	RollsPerLeg:     5,
+	LegTicketValues: []int{5, 3, 2},
+	BoardSize:       DefaultBoardSize,
+}
+
+func (rs *RuleSet) HasCamel(c Color) bool {
+	for _, o := range rs.Camels {
+		if o == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *RuleSet) HasCrazyCamels() bool {
+	return len(rs.Camels) > NumRacingCamels
+}
+
+// Returns the number of values of the die, and how many times each of them
+// counts so that every die has as many outcomes as the grey die.
+func (rs *RuleSet) dieValues(c Color) (RollValue, int) {
+	if c == rs.GreyDie {
+		return 6, 1
+	}
+	return 3, 2
+}
+
+// Returns the roll of the die with the given value, out of dieValues.
+func (rs *RuleSet) dieRoll(c Color, v RollValue) DieRoll {
+	if c != rs.GreyDie {
+		return DieRoll{c, v}
+	}
+	if v > 3 {
+		return DieRoll{rs.CrazyCamels[1], v - 3}
+	}
+	return DieRoll{rs.CrazyCamels[0], v}
+}
+
+// Returns the die that rolls the camel.
+func (rs *RuleSet) camelDie(c Color) Color {
+	if rs.GreyDie != NoColor && (c == rs.CrazyCamels[0] || c == rs.CrazyCamels[1]) {
+		return rs.GreyDie
+	}
+	return c
+}
+
+// Returns the crazy camel other than the given one.
+func (rs *RuleSet) otherCrazyCamel(c Color) Color {
+	if c == rs.CrazyCamels[0] {
+		return rs.CrazyCamels[1]
+	}
+	return rs.CrazyCamels[0]
+}
+
+// Returns the number of dice that stay in the pyramid at the end of a leg.
+func (rs *RuleSet) diceLeftPerLeg() int {
+	return len(rs.Dice) - rs.RollsPerLeg
+}
+
+func (rs *RuleSet) validate() error {
+	if rs.BoardSize < MinBoardSize || rs.BoardSize > MaxBoardSize {
+		return fmt.Errorf("invalid board size: %d, need %d-%d", rs.BoardSize, MinBoardSize, MaxBoardSize)
+	}
+	if rs.RollsPerLeg < 1 || rs.RollsPerLeg > NumMovesPerLeg || rs.RollsPerLeg > len(rs.Dice) {
+		return fmt.Errorf("invalid number of rolls per leg: %d", rs.RollsPerLeg)
+	}
+	if rs.HasCrazyCamels() != (rs.GreyDie != NoColor) {
+		return errors.New("need a grey die for the crazy camels, and only for them")
+	}
+	return nil
+}
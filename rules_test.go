@@ -0,0 +1,219 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFirstEditionLegRankingDistribution(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// All the dice are rolled in a leg: green catches up with blue in 7 out
+	// of the 18 roll sequences.
+	g, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			1: {Red, Yellow, Purple},
+			8: {Green},
+			9: {Blue},
+		},
+		DiePyramid: FirstEdition.NewDiePyramidWithDice(r, []Color{Green, Blue}),
+		Rules:      FirstEdition,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &RankingDistribution{
+		TotalRankings: 72,
+		Rankings: [NumRacingCamels][NumRacingCamels]int{
+			Green:  {0, 0, 0, 44, 28},
+			Yellow: {0, 72, 0, 0, 0},
+			Red:    {72, 0, 0, 0, 0},
+			Blue:   {0, 0, 0, 28, 44},
+			Purple: {0, 0, 72, 0, 0},
+		},
+	}
+	if got := g.ComputeLegRankingDistribution(); *got != *want {
+		t.Errorf("ComputeLegRankingDistribution() got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFirstEditionGame(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameWithRules(FirstEdition, []string{"A", "B", "C", "D", "E", "F"}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Rules() != FirstEdition {
+		t.Errorf("want the first edition rules, got %s", g.Rules().Name)
+	}
+	for c := Black; c <= White; c++ {
+		if g.camelTokens[c].Position != NoPosition {
+			t.Errorf("want no %s camel on the board, got:\n%s", c, g)
+		}
+	}
+	if g.diePyramid.RemainingRolls() != 5 || len(g.diePyramid.RemainingDice()) != 5 {
+		t.Errorf("want all 5 dice to be rolled, got %v", g.diePyramid.RemainingDice())
+	}
+	// No camel can cross the finish line in the first leg.
+	if d := g.ComputeLegRankingDistribution(); d.TotalRankings != 120*6*6*6*6*6 {
+		t.Errorf("want %d total rankings, got %d", 120*6*6*6*6*6, d.TotalRankings)
+	}
+	for _, v := range FirstEdition.LegTicketValues {
+		if ticket, err := g.takeLegTicket(Red); err != nil || ticket.Value != v {
+			t.Errorf("want %d ticket, got %v, %v", v, ticket, err)
+		}
+	}
+	if _, ok := g.TopLegTicket(Red); ok {
+		t.Error("want no red tickets left after 3 taken")
+	}
+	if err := g.ApplyMove(Move{Type: MakePact, Player: 0, Partner: 1}); err == nil {
+		t.Error("want error making a pact with the first edition rules")
+	}
+	if err := g.ApplyMove(Move{Type: RollDie, Player: g.CurrentPlayer(), DieRoll: DieRoll{Black, 1}}); err == nil {
+		t.Error("want error rolling the grey die with the first edition rules")
+	}
+	for c := Green; c <= Purple; c++ {
+		if err := g.ApplyMove(Move{Type: RollDie, Player: g.CurrentPlayer(), DieRoll: DieRoll{c, 1}}); err != nil {
+			t.Fatalf("rolling %s: %v", c, err)
+		}
+	}
+	if g.legMovesIndex != 0 || g.diePyramid.RemainingRolls() != 5 {
+		t.Errorf("want a new leg after 5 rolls, got:\n%s", g)
+	}
+}
+
+func TestFirstEditionFailure(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	racing := map[BoardPosition][]Color{0: {Green, Yellow, Red, Blue, Purple}}
+	for _, tc := range []struct {
+		desc  string
+		input *GameStateInput
+	}{
+		{
+			desc: "crazy camel",
+			input: &GameStateInput{
+				Camels: map[BoardPosition][]Color{0: {Green, Yellow, Red, Blue, Purple}, 14: {Black}},
+				Rules:  FirstEdition,
+			},
+		},
+		{
+			desc:  "second edition pyramid",
+			input: &GameStateInput{Camels: racing, DiePyramid: NewDiePyramid(r), Rules: FirstEdition},
+		},
+		{
+			desc:  "too many tickets taken",
+			input: &GameStateInput{Camels: racing, LegTicketsTaken: map[Color]int{Red: 4}, Rules: FirstEdition},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if _, err := NewGameFromState(tc.input); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
+
+func TestBoardSize(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	short := *SecondEdition
+	short.BoardSize = 8
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob"},
+		Camels: map[BoardPosition][]Color{
+			0: {Green, Yellow, Red, Blue},
+			6: {Purple},
+			7: {Black, White},
+		},
+		Rules: &short,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.FinishPosition() != 7 {
+		t.Errorf("want finish position 7, got %d", g.FinishPosition())
+	}
+	if err := g.ApplyMove(Move{Type: RollDie, Player: 0, DieRoll: DieRoll{Purple, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if !g.gameOver || g.camelTokens[Purple].Position != 0 || g.ranking[First] != Purple {
+		t.Errorf("want purple to win crossing the finish of the short board, got:\n%s", g)
+	}
+
+	long := *SecondEdition
+	long.BoardSize = 20
+	for range numSamples {
+		g, err := NewGameWithRules(&long, []string{"Alice", "Bob"}, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for c := Black; c <= White; c++ {
+			if p := g.camelTokens[c].Position; p < 17 || p > 19 {
+				t.Fatalf("want %s camel on spaces 18-20, got:\n%s", c, g)
+			}
+		}
+	}
+
+	for _, size := range []int{0, MinBoardSize - 1, MaxBoardSize + 1} {
+		bad := *SecondEdition
+		bad.BoardSize = size
+		if _, err := NewGameWithRules(&bad, []string{"Alice", "Bob"}, r); err == nil {
+			t.Errorf("want error creating a game on a board of size %d", size)
+		}
+	}
+}
+
+func TestGreyDie(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// The same game with the grey die colored white, and its values the
+	// other way around.
+	variant := *SecondEdition
+	variant.Dice = []Color{Green, Yellow, Red, Blue, Purple, White}
+	variant.GreyDie = White
+	variant.CrazyCamels = [2]Color{White, Black}
+	camels := map[BoardPosition][]Color{
+		0:  {Blue, Green},
+		2:  {Red, Yellow, Purple},
+		9:  {White},
+		11: {Black},
+	}
+	g, err := NewGameFromState(&GameStateInput{
+		Camels:     camels,
+		DiePyramid: NewDiePyramidWithDice(r, []Color{Green, Red, Black}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewGameFromState(&GameStateInput{
+		Players:    []string{"Alice", "Bob"},
+		Camels:     camels,
+		DiePyramid: variant.NewDiePyramidWithDice(r, []Color{Green, Red, White}),
+		Rules:      &variant,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := g.ComputeLegRankingDistribution()
+	if got := v.ComputeLegRankingDistribution(); *got != *want {
+		t.Errorf("ComputeLegRankingDistribution() got:\n%s\nwant:\n%s", got, want)
+	}
+	got := &RankingDistribution{}
+	v.enumerateLeg(func(weight int) {
+		got.RecordWeightedRanking(&v.ranking, weight)
+	})
+	if *got != *want {
+		t.Errorf("enumerateLeg() got:\n%s\nwant:\n%s", got, want)
+	}
+	if err := v.ApplyMove(Move{Type: RollDie, Player: 0, DieRoll: DieRoll{Black, 1}}); err != nil {
+		t.Fatalf("rolling the black camel with the white grey die: %v", err)
+	}
+	if dice := v.diePyramid.RemainingDice(); len(dice) != 2 || slices.Contains(dice, White) {
+		t.Errorf("want the grey die taken, got %v", dice)
+	}
+
+	bad := *SecondEdition
+	bad.GreyDie = NoColor
+	if _, err := NewGameWithRules(&bad, []string{"Alice", "Bob"}, r); err == nil {
+		t.Error("want error for crazy camels without a grey die")
+	}
+}
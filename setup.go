@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	MinPlayers = 2
+	MaxPlayers = 8
+)
+
+// Creates a new game, setting up the camels with the dice as in the official
+// rules: every racing die is rolled in pyramid order, and its camel is placed
+// on space 1, 2 or 3 by the value, on top of any camels already there. Then
+// the grey die is rolled twice, the first roll placing the crazy camel it
+// shows and the second one the other crazy camel, on the last, second to last
+// or third to last space by the value.
+func NewGame(players []string, r *rand.Rand) (*Game, error) {
+	return NewGameWithRules(SecondEdition, players, r)
+}
+
+// Creates a new game with the given rule set, set up as in NewGame. The grey
+// die is only rolled if the rule set has crazy camels.
+func NewGameWithRules(rules *RuleSet, players []string, r *rand.Rand) (*Game, error) {
+	if len(players) < MinPlayers || len(players) > MaxPlayers {
+		return nil, fmt.Errorf("invalid number of players: %d, need %d-%d", len(players), MinPlayers, MaxPlayers)
+	}
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
+	p := rules.NewDiePyramid(r)
+	camels := make(map[BoardPosition][]Color)
+	for _, c := range p.RemainingDice() {
+		if c.IsCrazy() {
+			continue
+		}
+		roll := p.rollDie(c)
+		pos := StartPosition.Add(int(roll.Value)-1, rules.BoardSize)
+		camels[pos] = append(camels[pos], c)
+	}
+	if rules.HasCrazyCamels() {
+		finish := BoardPosition(rules.BoardSize - 1)
+		first := p.rollDie(rules.GreyDie)
+		second := p.rollDie(rules.GreyDie)
+		second.Color = rules.otherCrazyCamel(first.Color)
+		for _, roll := range []DieRoll{first, second} {
+			pos := finish.Add(1-int(roll.Value), rules.BoardSize)
+			camels[pos] = append(camels[pos], roll.Color)
+		}
+	}
+	p.Reset()
+	return NewGameFromState(&GameStateInput{Players: players, Camels: camels, DiePyramid: p, Rules: rules})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestNewGame(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var positions [NumCamels][DefaultBoardSize]int
+	for range numSamples {
+		g, err := NewGame([]string{"Alice", "Bob"}, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.diePyramid.RemainingRolls() != NumMovesPerLeg || g.legMovesIndex != 0 {
+			t.Fatalf("want a full pyramid, got %v", g.diePyramid.RemainingDice())
+		}
+		if g.NumPlayers() != 2 || g.Coins(0) != startingCoins || g.CurrentPlayer() != 0 {
+			t.Fatalf("want fresh players, got:\n%s", g)
+		}
+		for c := Green; c <= White; c++ {
+			positions[c][g.camelTokens[c].Position]++
+		}
+	}
+	for c := Green; c <= White; c++ {
+		start := StartPosition
+		if c.IsCrazy() {
+			start = DefaultBoardSize - 3
+		}
+		placed := 0
+		for p := start; p <= start+2; p++ {
+			if positions[c][p] == 0 {
+				t.Errorf("want %s camel on space %d sometimes, got %v", c, p+1, positions[c])
+			}
+			placed += positions[c][p]
+		}
+		if placed != numSamples {
+			t.Errorf("want %s camel only on spaces %d-%d, got %v", c, start+1, start+3, positions[c])
+		}
+	}
+}
+
+func TestNewGameFailure(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, players := range [][]string{
+		{"Alice"},
+		{"A", "B", "C", "D", "E", "F", "G", "H", "I"},
+	} {
+		if _, err := NewGame(players, r); err == nil {
+			t.Errorf("want error creating a game with %d players", len(players))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The outcome of placing a cheer/boo tile on a space for the rest of the leg.
+type TilePlacement struct {
+	Move Move    // A PlaceCheer or PlaceBoo move.
+	EV   float64 // Expected coins from camels landing on the tile.
+	// The change in each camel's leg ranking probabilities caused by the tile,
+	// Color x Rank.
+	RankingShift [NumRacingCamels][NumRacingCamels]float64
+}
+
+// Tries both sides of the player's tile on every space where the player can
+// place it, and returns the resulting placements from the most to the least
+// profitable. If the player's tile is already on the board, the placements
+// move it, and the ranking shifts are relative to the current board.
+func (g *Game) OptimizeTilePlacement(player Player) []TilePlacement {
+	var result []TilePlacement
+	base := g.ComputeLegRankingDistribution()
+	ownType, ownPos := g.liftTile(player)
+	defer g.dropTile(ownType, player, ownPos)
+	for _, p := range g.LegalTileSpaces(player) {
+		for _, t := range []MoveType{PlaceCheer, PlaceBoo} {
+			tp := TilePlacement{Move: Move{Type: t, Player: player, Position: p}}
+			s := &g.boardSpaces[p]
+			s.setTile(t, player)
+			d, l := g.computeLegDistributions()
+			s.setTile(t, NoPlayer)
+			tp.EV = l.ExpectedLandings(p)
+			for c := Green; c < Black; c++ {
+				for r := Last; r <= First; r++ {
+					tp.RankingShift[c][r] = d.Probability(c, r) - base.Probability(c, r)
+				}
+			}
+			result = append(result, tp)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].EV > result[j].EV
+	})
+	return result
+}
+
+// Returns the spaces where the player can place their spectator tile.
+func (g *Game) LegalTileSpaces(player Player) []BoardPosition {
+	var result []BoardPosition
+	for p := StartPosition + 1; p <= g.FinishPosition(); p++ {
+		if g.checkTileSpace(PlaceCheer, p, player) == nil {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// Checks that the player can place a cheer (PlaceCheer) or boo (PlaceBoo) tile
+// on the space: not on the start space, not on camels or another tile, and not
+// next to another tile. The player's own tile doesn't count, as they can move
+// it. With NoPlayer, every tile on the board counts.
+func (g *Game) checkTileSpace(t MoveType, p BoardPosition, player Player) error {
+	if p > g.FinishPosition() || p <= StartPosition {
+		return fmt.Errorf("invalid board position: %d", p)
+	}
+	name := strings.ToLower(t.String())
+	if g.boardSpaces[p].Stack.Height > 0 || g.hasOtherTile(p, player) {
+		return fmt.Errorf("invalid %s position %d, not empty", name, p)
+	}
+	for _, q := range []BoardPosition{p - 1, p + 1} {
+		if q > StartPosition && q <= g.FinishPosition() && g.hasOtherTile(q, player) {
+			return fmt.Errorf("invalid %s position %d, next to another spectator tile", name, p)
+		}
+	}
+	return nil
+}
+
+// Returns whether the space has a tile that is not the player's.
+func (g *Game) hasOtherTile(p BoardPosition, player Player) bool {
+	s := &g.boardSpaces[p]
+	return s.HasCheer() && s.Cheer != player || s.HasBoo() && s.Boo != player
+}
+
+// Places the player's cheer (PlaceCheer) or boo (PlaceBoo) tile on the space,
+// moving it if it is already on the board.
+func (g *Game) placeTile(t MoveType, player Player, p BoardPosition) error {
+	if err := g.checkTileSpace(t, p, player); err != nil {
+		return err
+	}
+	g.liftTile(player)
+	g.dropTile(t, player, p)
+	g.players[player].Tile = p
+	return nil
+}
+
+// Takes the player's tile off the board, if it is there, and returns which
+// side was up and where it was.
+func (g *Game) liftTile(player Player) (MoveType, BoardPosition) {
+	p := g.players[player].Tile
+	if p == NoPosition {
+		return PlaceCheer, p
+	}
+	s := &g.boardSpaces[p]
+	if s.Cheer == player {
+		s.Cheer = NoPlayer
+		return PlaceCheer, p
+	}
+	s.Boo = NoPlayer
+	return PlaceBoo, p
+}
+
+// Puts the player's tile on the space, if it is a board position.
+func (g *Game) dropTile(t MoveType, player Player, p BoardPosition) {
+	if p != NoPosition {
+		g.boardSpaces[p].setTile(t, player)
+	}
+}
+
+// Resolves the owner of a spectator tile on the space in a game state input,
+// and records the tile as theirs.
+func (g *Game) tileOwner(name string, p BoardPosition) (Player, error) {
+	owner, err := g.playerByName(name)
+	if err != nil {
+		return NoPlayer, err
+	}
+	if g.players[owner].Tile != NoPosition {
+		return NoPlayer, fmt.Errorf("%s has more than one spectator tile", name)
+	}
+	g.players[owner].Tile = p
+	return owner, nil
+}
+
+// Sets the owner of the cheer (PlaceCheer) or boo (PlaceBoo) tile on the
+// space. NoPlayer removes the tile.
+func (s *boardSpace) setTile(t MoveType, player Player) {
+	if t == PlaceCheer {
+		s.Cheer = player
+	} else {
+		s.Boo = player
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeLegLandingDistribution(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice"},
+		Camels: map[BoardPosition][]Color{
+			0:  {Yellow, Green, Red},
+			5:  {Blue},
+			9:  {Purple},
+			13: {Black, White},
+		},
+		Cheers: map[BoardPosition]string{
+			7: "Alice",
+		},
+		DiePyramid: NewDiePyramidWithDice(r, []Color{Blue, Purple, Red}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each die is rolled in 2/3 of the outcomes, with each value 1/3 of the
+	// time. The cheer on 8 moves Blue on to 9, but it still landed on 8.
+	want := &LandingDistribution{
+		BoardSize:     DefaultBoardSize,
+		TotalOutcomes: 216,
+		Landings:      [MaxBoardSize]int{1: 48, 2: 48, 3: 48, 6: 48, 7: 48, 8: 48, 10: 48, 11: 48, 12: 48},
+	}
+	got := g.ComputeLegLandingDistribution()
+	if *got != *want {
+		t.Errorf("want landing distribution:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestOptimizeTilePlacement(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob"},
+		Camels: map[BoardPosition][]Color{
+			0:  {Yellow, Green, Red},
+			5:  {Blue},
+			9:  {Purple},
+			13: {Black, White},
+		},
+		DiePyramid: NewDiePyramidWithDice(r, []Color{Blue, Purple}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	placements := g.OptimizeTilePlacement(1)
+	if len(placements) != 2*12 {
+		t.Fatalf("want %d placements, got %d", 2*12, len(placements))
+	}
+	found := false
+	for _, tp := range placements {
+		if tp.Move != (Move{Type: PlaceCheer, Player: 1, Position: 8}) {
+			continue
+		}
+		found = true
+		if math.Abs(tp.EV-float64(1)/6) > 1e-9 {
+			t.Errorf("want EV 1/6, got %f", tp.EV)
+		}
+		// A Blue 3 would now land on top of Purple.
+		var want [NumRacingCamels][NumRacingCamels]float64
+		want[Blue][First] = float64(1) / 6
+		want[Blue][First-1] = -float64(1) / 6
+		want[Purple][First] = -float64(1) / 6
+		want[Purple][First-1] = float64(1) / 6
+		for c := Green; c < Black; c++ {
+			for r := Last; r <= First; r++ {
+				if math.Abs(tp.RankingShift[c][r]-want[c][r]) > 1e-9 {
+					t.Errorf("want ranking shift %f for %s at rank %d, got %f", want[c][r], c, r, tp.RankingShift[c][r])
+				}
+			}
+		}
+	}
+	if !found {
+		t.Error("missing placement of a cheer on space 9")
+	}
+	if g.HasCheer(8) || g.HasBoo(8) {
+		t.Error("want tiles removed after optimization")
+	}
+}
+
+func TestTileOwnersFromState(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob", "Carol"},
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green},
+			3:  {Red, Blue},
+			8:  {Purple},
+			14: {White, Black},
+		},
+		Cheers: map[BoardPosition]string{2: "Carol"},
+		Boos:   map[BoardPosition]string{10: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.boardSpaces[2].Cheer != 2 || g.SpectatorTile(2) != 2 {
+		t.Errorf("want Carol's cheer on space 3, got:\n%s", g.fullString())
+	}
+	if g.boardSpaces[10].Boo != 1 || g.SpectatorTile(1) != 10 {
+		t.Errorf("want Bob's boo on space 11, got:\n%s", g.fullString())
+	}
+	if g.SpectatorTile(0) != NoPosition {
+		t.Errorf("want Alice's tile in hand, got %d", g.SpectatorTile(0))
+	}
+	// Green lands on Carol's cheer, who gets paid for it.
+	g.applyCamelMove(&DieRoll{Green, 1})
+	g.scoreLeg()
+	if g.Coins(2) != startingCoins+1 || g.Coins(0) != startingCoins {
+		t.Errorf("want Carol paid for the landing, got:\n%s", g)
+	}
+}
+
+func TestLegalTileSpaces(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob"},
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green},
+			3:  {Red, Blue},
+			8:  {Purple},
+			14: {White, Black},
+		},
+		Cheers: map[BoardPosition]string{5: "Alice"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		player Player
+		want   []BoardPosition
+	}{
+		// Alice can move her own tile, including to the spaces next to it.
+		{0, []BoardPosition{2, 4, 5, 6, 7, 9, 10, 11, 12, 13, 15}},
+		{1, []BoardPosition{2, 7, 9, 10, 11, 12, 13, 15}},
+	}
+	for _, tc := range testCases {
+		if got := g.LegalTileSpaces(tc.player); !slices.Equal(got, tc.want) {
+			t.Errorf("want legal tile spaces %v for %s, got %v", tc.want, g.PlayerName(tc.player), got)
+		}
+	}
+	if err := g.placeTile(PlaceBoo, 0, 6); err != nil {
+		t.Fatal(err)
+	}
+	if g.HasCheer(5) || !g.HasBoo(6) || g.SpectatorTile(0) != 6 {
+		t.Errorf("want Alice's tile moved to space 7, got:\n%s", g.fullString())
+	}
+	if _, err := NewGameFromState(&GameStateInput{
+		Players: []string{"Alice", "Bob"},
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green},
+			3:  {Red, Blue},
+			8:  {Purple},
+			14: {White, Black},
+		},
+		Cheers: map[BoardPosition]string{5: "Alice"},
+		Boos:   map[BoardPosition]string{6: "Bob"},
+	}); err == nil || !strings.Contains(err.Error(), "next to another spectator tile") {
+		t.Errorf("want error with adjacent tiles in input, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import "fmt"
+
+// A leg betting ticket. Each racing camel has a stack of them, and they are
+// always taken from the top.
+type LegTicket struct {
+	Color Color
+	Value int // Paid if the camel wins the leg.
+}
+
+func (t LegTicket) String() string {
+	return fmt.Sprintf("%s %d", t.Color, t.Value)
+}
+
+// Returns the coins the ticket pays (or costs) at the end of the leg, given
+// the final rank of its camel.
+func (t LegTicket) Payout(r Rank) int {
+	switch r {
+	case First:
+		return t.Value
+	case First - 1:
+		return 1
+	}
+	return -1
+}
+
+// Returns the expected coin value of the ticket for the given leg ranking
+// distribution.
+func (t LegTicket) ExpectedValue(d *RankingDistribution) float64 {
+	ev := 0.0
+	for r := Last; r <= First; r++ {
+		ev += float64(t.Payout(r)) * d.Probability(t.Color, r)
+	}
+	return ev
+}
+
+// Returns the expected total coin value of holding all the tickets at once,
+// such as two tickets of a player.
+func (d *PairDistribution) TicketsExpectedValue(tickets []LegTicket) float64 {
+	return d.expectedValue(func(first, second Color) int {
+		payout := 0
+		for _, t := range tickets {
+			payout += t.pairPayout(first, second)
+		}
+		return payout
+	})
+}
+
+// Returns the expected total coin value of holding the tickets in a pact with
+// a partner holding the partner tickets: the partner's best ticket pays out
+// too, unless it would lose coins.
+func (d *PairDistribution) PactExpectedValue(tickets, partnerTickets []LegTicket) float64 {
+	return d.expectedValue(func(first, second Color) int {
+		payout, best := 0, 0
+		for _, t := range tickets {
+			payout += t.pairPayout(first, second)
+		}
+		for _, t := range partnerTickets {
+			best = max(best, t.pairPayout(first, second))
+		}
+		return payout + best
+	})
+}
+
+// Returns the expected value of the payout of the first and second camels.
+func (d *PairDistribution) expectedValue(payout func(first, second Color) int) float64 {
+	ev := 0.0
+	for first := range d.Pairs {
+		for second, n := range d.Pairs[first] {
+			if n != 0 {
+				ev += float64(payout(Color(first), Color(second))) * float64(n)
+			}
+		}
+	}
+	return ev / float64(d.TotalRankings)
+}
+
+// Returns the ticket's payout when the given camels finish the leg first and
+// second.
+func (t LegTicket) pairPayout(first, second Color) int {
+	switch t.Color {
+	case first:
+		return t.Payout(First)
+	case second:
+		return t.Payout(First - 1)
+	}
+	return t.Payout(Last)
+}
+
+// Returns the ticket at the top of the camel's stack, if there are any left.
+func (g *Game) TopLegTicket(c Color) (LegTicket, bool) {
+	values := g.rules.LegTicketValues
+	if !c.IsRacing() || g.legTicketsTaken[c] == len(values) {
+		return LegTicket{}, false
+	}
+	return LegTicket{c, values[g.legTicketsTaken[c]]}, true
+}
+
+// Takes the ticket at the top of the camel's stack.
+func (g *Game) takeLegTicket(c Color) (LegTicket, error) {
+	if err := checkRacingCamel(c); err != nil {
+		return LegTicket{}, err
+	}
+	t, ok := g.TopLegTicket(c)
+	if !ok {
+		return t, fmt.Errorf("no leg tickets left for %s camel", c)
+	}
+	g.legTicketsTaken[c]++
+	return t, nil
+}
+
+type LegTicketEV struct {
+	Ticket LegTicket
+	EV     float64
+}
+
+// Returns the expected coin value of taking the top ticket of each camel
+// that still has tickets left, for the given leg ranking distribution.
+func (g *Game) LegTicketEVs(d *RankingDistribution) []LegTicketEV {
+	var result []LegTicketEV
+	for c := Green; c < Black; c++ {
+		if t, ok := g.TopLegTicket(c); ok {
+			result = append(result, LegTicketEV{t, t.ExpectedValue(d)})
+		}
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLegTicketEVs(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			1:  {Yellow, Green, Red},
+			5:  {Blue},
+			9:  {Purple},
+			13: {Black, White},
+		},
+		LegTicketsTaken: map[Color]int{Purple: 1, Green: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &RankingDistribution{
+		TotalRankings: 216,
+		Rankings: [NumRacingCamels][NumRacingCamels]int{
+			Green:  {0, 216, 0, 0, 0},
+			Yellow: {216, 0, 0, 0, 0},
+			Red:    {0, 0, 216, 0, 0},
+			Blue:   {0, 0, 0, 180, 36},
+			Purple: {0, 0, 0, 36, 180},
+		},
+	}
+	want := []LegTicketEV{
+		{LegTicket{Yellow, 5}, -1},
+		{LegTicket{Red, 5}, -1},
+		{LegTicket{Blue, 5}, float64(180+5*36) / 216},
+		{LegTicket{Purple, 3}, float64(36+3*180) / 216},
+	}
+	got := g.LegTicketEVs(d)
+	if len(got) != len(want) {
+		t.Fatalf("want EVs %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i].Ticket != want[i].Ticket || math.Abs(got[i].EV-want[i].EV) > 1e-9 {
+			t.Errorf("want EV %v, got %v", want[i], got[i])
+		}
+	}
+}
+
+func TestTakeLegTicket(t *testing.T) {
+	g, err := NewGameFromState(&GameStateInput{
+		Camels: map[BoardPosition][]Color{
+			0: {Yellow, Green, Red, Blue, Purple, Black, White},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range SecondEdition.LegTicketValues {
+		ticket, err := g.takeLegTicket(Red)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ticket != (LegTicket{Red, v}) {
+			t.Errorf("want ticket %s %d, got %s", Red, v, ticket)
+		}
+	}
+	if _, err := g.takeLegTicket(Red); err == nil {
+		t.Error("want error taking a ticket from an empty stack")
+	}
+	if _, err := g.takeLegTicket(White); err == nil {
+		t.Error("want error taking a ticket for a crazy camel")
+	}
+	if ticket, ok := g.TopLegTicket(Blue); !ok || ticket.Value != 5 {
+		t.Errorf("want top ticket %s 5, got %s", Blue, ticket)
+	}
+}
+
+func TestTicketsExpectedValue(t *testing.T) {
+	d := &PairDistribution{TotalRankings: 72}
+	d.Pairs[Green][Blue] = 28
+	d.Pairs[Blue][Green] = 44
+	for _, tc := range []struct {
+		tickets []LegTicket
+		want    float64
+	}{
+		{nil, 0},
+		{[]LegTicket{{Green, 5}}, float64(28*5+44) / 72},
+		{[]LegTicket{{Green, 5}, {Blue, 3}}, float64(28*6+44*4) / 72},
+		{[]LegTicket{{Red, 5}, {Blue, 2}}, float64(28*0+44*1) / 72},
+	} {
+		if got := d.TicketsExpectedValue(tc.tickets); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("TicketsExpectedValue(%v) = %f, want %f", tc.tickets, got, tc.want)
+		}
+	}
+}
+
+func TestPactExpectedValue(t *testing.T) {
+	d := &PairDistribution{TotalRankings: 72}
+	d.Pairs[Green][Blue] = 28
+	d.Pairs[Blue][Green] = 44
+	for _, tc := range []struct {
+		tickets, partnerTickets []LegTicket
+		want                    float64
+	}{
+		{nil, nil, 0},
+		{[]LegTicket{{Green, 5}}, nil, float64(28*5+44) / 72},
+		// A partner ticket that would lose coins pays nothing.
+		{nil, []LegTicket{{Red, 5}}, 0},
+		{[]LegTicket{{Red, 5}}, []LegTicket{{Green, 5}, {Blue, 3}}, float64(28*(-1+5)+44*(-1+3)) / 72},
+		{[]LegTicket{{Green, 2}}, []LegTicket{{Green, 3}, {Yellow, 5}}, float64(28*(2+3)+44*(1+1)) / 72},
+	} {
+		if got := d.PactExpectedValue(tc.tickets, tc.partnerTickets); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("PactExpectedValue(%v, %v) = %f, want %f", tc.tickets, tc.partnerTickets, got, tc.want)
+		}
+	}
+}